@@ -0,0 +1,233 @@
+package telegram
+
+import (
+	"context"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type (
+	// Album represents messages of the one media group, which are sent by user at once.
+	Album struct {
+		// Identifier of the media group
+		MediaGroupID string
+
+		// Conversation the album belongs to
+		Chat *Chat
+
+		// Messages of the album ordered by identifiers
+		Messages []*Message
+
+		// Media items of the album in the same order as Messages
+		Items []*AlbumItem
+	}
+
+	// AlbumItem represents media of the one album message.
+	AlbumItem struct {
+		// Message with the media
+		Message *Message
+
+		// Caption of the media, if any
+		Caption string
+
+		// Special entities like usernames, URLs, bot commands, etc. that appear in the caption
+		CaptionEntities []*MessageEntity
+
+		// Available sizes of the photo, if the item is a photo
+		Photo Photo
+
+		// Video, if the item is a video
+		Video *Video
+
+		// Document, if the item is a document
+		Document *Document
+
+		// Audio, if the item is an audio file
+		Audio *Audio
+	}
+
+	// AlbumHandler handles collected albums.
+	AlbumHandler func(ctx context.Context, a *Album) error
+
+	// AlbumAggregator collects messages of media groups until no new messages of the group are received during the
+	// quiet period, then passes them to the AlbumHandler as one Album.
+	//
+	// AlbumHandler is called in the separate goroutine after the quiet period, so it's not ordered with other
+	// updates of the same chat, even under Dispatcher: the next message after the album may be handled before it.
+	AlbumAggregator struct {
+		handler      AlbumHandler
+		quiet        time.Duration
+		errorHandler ErrorHandler
+		mu           sync.Mutex
+		albums       map[string]*pendingAlbum
+	}
+
+	pendingAlbum struct {
+		messages []*Message
+		timer    *time.Timer
+	}
+)
+
+// DefaultAlbumQuietPeriod is a default delay after the last message of media group before the album is handled.
+const DefaultAlbumQuietPeriod time.Duration = time.Second
+
+// NewAlbumAggregator creates a new AlbumAggregator which calls h for albums after the quiet period (or
+// DefaultAlbumQuietPeriod if it's not positive) since the last message of the media group.
+func NewAlbumAggregator(quiet time.Duration, h AlbumHandler) *AlbumAggregator {
+	if quiet <= 0 {
+		quiet = DefaultAlbumQuietPeriod
+	}
+
+	return &AlbumAggregator{
+		handler: h,
+		quiet:   quiet,
+		albums:  make(map[string]*pendingAlbum),
+	}
+}
+
+// SetErrorHandler sets the callback for errors returned by AlbumHandler after the quiet period and its panics
+// recovered as PanicError, while Flush returns them instead. Without the callback they are only printed in debug
+// logs.
+func (a *AlbumAggregator) SetErrorHandler(h ErrorHandler) {
+	a.errorHandler = h
+}
+
+// HandleMessage collects the message of media group, messages without media group are ignored. Use it with the
+// MediaGroup filter, like:
+//
+//	r.OnMessage(albums.HandleMessage, telegram.MediaGroup())
+func (a *AlbumAggregator) HandleMessage(_ context.Context, m *Message) error {
+	if m == nil || m.MediaGroupID == "" {
+		return nil
+	}
+
+	key := albumKey(m)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if p, ok := a.albums[key]; ok {
+		p.messages = append(p.messages, m)
+		p.timer.Reset(a.quiet)
+
+		return nil
+	}
+
+	p := &pendingAlbum{messages: []*Message{m}}
+	p.timer = time.AfterFunc(a.quiet, func() {
+		if album := a.take(key, p); album != nil {
+			a.handle(album)
+		}
+	})
+	a.albums[key] = p
+
+	return nil
+}
+
+// Flush immediately handles all collected albums without waiting of the quiet period, like before shutdown, and
+// returns the first error returned by AlbumHandler.
+func (a *AlbumAggregator) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	pending := make(map[string]*pendingAlbum, len(a.albums))
+
+	for key, p := range a.albums {
+		pending[key] = p
+	}
+	a.mu.Unlock()
+
+	var result error
+
+	for key, p := range pending {
+		album := a.take(key, p)
+		if album == nil {
+			continue
+		}
+
+		if err := a.handler(ctx, album); err != nil && result == nil {
+			result = err
+		}
+	}
+
+	return result
+}
+
+// handle calls AlbumHandler in the timer goroutine, where nobody else can recover its panic.
+func (a *AlbumAggregator) handle(album *Album) {
+	defer func() {
+		if r := recover(); r != nil {
+			a.errorHandler.handle("Failed to handle album:", PanicError{Value: r, Stack: debug.Stack()})
+		}
+	}()
+
+	if err := a.handler(context.Background(), album); err != nil {
+		a.errorHandler.handle("Failed to handle album:", err)
+	}
+}
+
+// take removes the pending album by key and builds the Album from it. It returns nil if the album is already
+// taken, so the album is handled once by timer or Flush.
+func (a *AlbumAggregator) take(key string, p *pendingAlbum) *Album {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.albums[key] != p {
+		return nil
+	}
+
+	delete(a.albums, key)
+	p.timer.Stop()
+
+	return NewAlbum(p.messages)
+}
+
+// NewAlbum creates a new Album from messages of the one media group in any order.
+func NewAlbum(messages []*Message) *Album {
+	a := &Album{
+		Messages: make([]*Message, len(messages)),
+		Items:    make([]*AlbumItem, len(messages)),
+	}
+
+	copy(a.Messages, messages)
+	sort.SliceStable(a.Messages, func(i, j int) bool { return a.Messages[i].ID < a.Messages[j].ID })
+
+	for i, m := range a.Messages {
+		a.Items[i] = &AlbumItem{
+			Message:         m,
+			Caption:         m.Caption,
+			CaptionEntities: m.CaptionEntities,
+			Photo:           m.Photo,
+			Video:           m.Video,
+			Document:        m.Document,
+			Audio:           m.Audio,
+		}
+	}
+
+	if len(a.Messages) > 0 {
+		a.MediaGroupID, a.Chat = a.Messages[0].MediaGroupID, a.Messages[0].Chat
+	}
+
+	return a
+}
+
+// Caption returns the first non-empty caption of the album items, which is shown by clients as the album caption.
+func (a Album) Caption() string {
+	for _, item := range a.Items {
+		if item.Caption != "" {
+			return item.Caption
+		}
+	}
+
+	return ""
+}
+
+// albumKey returns key of the media group, which is unique within the chat.
+func albumKey(m *Message) string {
+	if m.Chat == nil {
+		return m.MediaGroupID
+	}
+
+	return strconv.FormatInt(m.Chat.ID, 10) + ":" + m.MediaGroupID
+}
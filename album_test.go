@@ -0,0 +1,108 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlbumAggregator(t *testing.T) {
+	chat := &Chat{ID: 42, Type: ChatPrivate}
+	photo := Photo{{FileID: "photo"}}
+	video := &Video{FileID: "video"}
+	document := &Document{FileID: "document"}
+
+	t.Run("quiet period", func(t *testing.T) {
+		albums := make(chan *Album, 2)
+
+		a := NewAlbumAggregator(50*time.Millisecond, func(ctx context.Context, a *Album) error {
+			albums <- a
+
+			return nil
+		})
+
+		r := NewRouter()
+		r.OnMessage(a.HandleMessage, MediaGroup())
+
+		// NOTE(toby3d): album messages may come in any order and mixed with messages of other albums.
+		for _, m := range []*Message{
+			{ID: 2, Chat: chat, MediaGroupID: "first", Video: video},
+			{ID: 4, Chat: chat, MediaGroupID: "second", Document: document, Caption: "second"},
+			{ID: 1, Chat: chat, MediaGroupID: "first", Photo: photo, Caption: "first"},
+			{ID: 3, Chat: chat, MediaGroupID: "first", Document: document},
+		} {
+			assert.NoError(t, r.HandleUpdate(context.Background(), &Update{Message: m}))
+		}
+
+		byID := make(map[string]*Album)
+
+		for i := 0; i < 2; i++ {
+			select {
+			case album := <-albums:
+				byID[album.MediaGroupID] = album
+			case <-time.After(time.Second):
+				t.Fatal("album is not handled after quiet period")
+			}
+		}
+
+		first := byID["first"]
+		if assert.NotNil(t, first) && assert.Len(t, first.Items, 3) {
+			assert.Equal(t, chat, first.Chat)
+			assert.Equal(t, "first", first.Caption())
+			assert.Equal(t, []int64{1, 2, 3}, []int64{first.Messages[0].ID, first.Messages[1].ID,
+				first.Messages[2].ID})
+			assert.Equal(t, photo, first.Items[0].Photo)
+			assert.Equal(t, "first", first.Items[0].Caption)
+			assert.Equal(t, video, first.Items[1].Video)
+			assert.Empty(t, first.Items[1].Caption)
+			assert.Equal(t, document, first.Items[2].Document)
+		}
+
+		if second := byID["second"]; assert.NotNil(t, second) && assert.Len(t, second.Messages, 1) {
+			assert.Equal(t, "second", second.Caption())
+		}
+	})
+	t.Run("panic", func(t *testing.T) {
+		errs := make(chan error, 1)
+
+		a := NewAlbumAggregator(time.Millisecond, func(ctx context.Context, a *Album) error { panic("oops") })
+		a.SetErrorHandler(func(err error) { errs <- err })
+
+		assert.NoError(t, a.HandleMessage(context.Background(), &Message{ID: 1, Chat: chat, MediaGroupID: "abc"}))
+
+		select {
+		case err := <-errs:
+			panicErr, ok := err.(PanicError)
+			if assert.True(t, ok) {
+				assert.Equal(t, "oops", panicErr.Value)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("panic is not reported")
+		}
+	})
+	t.Run("flush", func(t *testing.T) {
+		errTest := errors.New("test")
+
+		var handled []*Album
+
+		a := NewAlbumAggregator(time.Hour, func(ctx context.Context, a *Album) error {
+			handled = append(handled, a)
+
+			return errTest
+		})
+
+		assert.NoError(t, a.HandleMessage(context.Background(), &Message{ID: 1, Chat: chat}))
+		assert.NoError(t, a.HandleMessage(context.Background(), &Message{
+			ID: 2, Chat: chat, MediaGroupID: "abc", Photo: photo,
+		}))
+		assert.Equal(t, errTest, a.Flush(context.Background()))
+		assert.NoError(t, a.Flush(context.Background()), "album must be handled once")
+
+		if assert.Len(t, handled, 1) {
+			assert.Equal(t, "abc", handled[0].MediaGroupID)
+		}
+	})
+}
@@ -0,0 +1,350 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+type (
+	// CallbackAnswerer is a middleware which answers callback queries after handling, so the button of the user
+	// does not spin until timeout if handler forgets to answer. Each callback query is answered exactly once.
+	CallbackAnswerer struct {
+		bot          *Bot
+		timeout      time.Duration
+		errorHandler ErrorHandler
+		mu           sync.Mutex
+		pending      map[string]map[*CallbackAnswer]struct{}
+	}
+
+	// CallbackAnswer represents the answer to the callback query which is being handled. Handlers get it by
+	// CallbackAnswerFromContext and can change it or answer early.
+	CallbackAnswer struct {
+		bot      *Bot
+		mu       sync.Mutex
+		params   AnswerCallbackQuery
+		answered bool
+	}
+
+	callbackAnswerKey struct{}
+)
+
+const (
+	// DefaultCallbackAnswerTimeout is a default duration of handling after which the callback query is answered
+	// by the empty answer to stop loading indicator.
+	DefaultCallbackAnswerTimeout time.Duration = 10 * time.Second
+
+	// callbackAnswerRequestTimeout limits the answer request sent by CallbackAnswerer itself.
+	callbackAnswerRequestTimeout time.Duration = 5 * time.Second
+)
+
+// ErrCallbackQueryAnswered describes a try to answer already answered callback query.
+var ErrCallbackQueryAnswered = errors.New("callback query already answered")
+
+// NewCallbackAnswerer creates a new CallbackAnswerer which answers callback queries by bot. It also adds the
+// interceptor into bot, so answers which are sent by handlers directly, like by bot.AnswerCallbackQuery, are not
+// repeated. Handlers must use the same bot or its copy created after this call.
+func NewCallbackAnswerer(b *Bot) *CallbackAnswerer {
+	ca := &CallbackAnswerer{
+		bot:     b,
+		timeout: DefaultCallbackAnswerTimeout,
+	}
+
+	if b != nil {
+		b.Use(ca.intercept)
+	}
+
+	return ca
+}
+
+// SetTimeout sets the duration of handling after which the callback query is answered by the empty answer, so the
+// later answer of handler is ignored. Zero disables it.
+func (ca *CallbackAnswerer) SetTimeout(d time.Duration) {
+	ca.timeout = d
+}
+
+// SetErrorHandler sets the callback for failed answers which can not be returned from handler: sent on timeout or
+// after handler already returned its own error. Without the callback they are only printed in debug logs.
+func (ca *CallbackAnswerer) SetErrorHandler(h ErrorHandler) {
+	ca.errorHandler = h
+}
+
+// Middleware wraps next handler, like Router.HandleUpdate, for answering callback queries. The answer is sent
+// after next is returned (or panicked) unless handler already answered by CallbackAnswer.Answer. Other updates are
+// passed to next as is. Use ReplyMiddleware for the Webhook ReplyHandler instead.
+func (ca *CallbackAnswerer) Middleware(next Handler) Handler {
+	return func(ctx context.Context, u *Update) (err error) {
+		if u.CallbackQuery == nil {
+			return next(ctx, u)
+		}
+
+		answer, end := ca.begin(u.CallbackQuery.ID)
+		defer end()
+
+		defer func() {
+			answerErr := ca.send(answer, false)
+			if answerErr == nil {
+				return
+			}
+
+			if err == nil {
+				err = answerErr
+
+				return
+			}
+
+			ca.errorHandler.handle("Failed to answer callback query:", answerErr)
+		}()
+
+		return next(context.WithValue(ctx, callbackAnswerKey{}, answer), u)
+	}
+}
+
+// ReplyMiddleware is like Middleware but wraps the Webhook ReplyHandler, which calls are not passed through the
+// interceptor of CallbackAnswerer. The answerCallbackQuery call returned by next is counted as the answer (or
+// dropped if the query is already answered), and if next returns no call the answer itself is returned as the
+// webhook reply. The answer is sent by the separate request before any other returned call.
+func (ca *CallbackAnswerer) ReplyMiddleware(next ReplyHandler) ReplyHandler {
+	return func(ctx context.Context, u *Update) *Call {
+		if u.CallbackQuery == nil {
+			return next(ctx, u)
+		}
+
+		answer, end := ca.begin(u.CallbackQuery.ID)
+		defer end()
+
+		returned := false
+
+		defer func() {
+			if returned {
+				return
+			}
+
+			if err := ca.send(answer, false); err != nil {
+				ca.errorHandler.handle("Failed to answer callback query:", err)
+			}
+		}()
+
+		call := next(context.WithValue(ctx, callbackAnswerKey{}, answer), u)
+		returned = true
+
+		return ca.reply(answer, call)
+	}
+}
+
+// begin creates the pending answer to the callback query with id, which is answered by the empty answer on
+// timeout, and returns the function to finish it.
+func (ca *CallbackAnswerer) begin(id string) (*CallbackAnswer, func()) {
+	answer := &CallbackAnswer{
+		bot:    ca.bot,
+		params: AnswerCallbackQuery{CallbackQueryID: id},
+	}
+
+	// NOTE(toby3d): the same query can be handled several times at once, like redelivered by Telegram, so each
+	// answer is kept separately.
+	ca.mu.Lock()
+	if ca.pending == nil {
+		ca.pending = make(map[string]map[*CallbackAnswer]struct{})
+	}
+
+	if ca.pending[id] == nil {
+		ca.pending[id] = make(map[*CallbackAnswer]struct{})
+	}
+
+	ca.pending[id][answer] = struct{}{}
+	ca.mu.Unlock()
+
+	var timer *time.Timer
+
+	if ca.timeout > 0 {
+		timer = time.AfterFunc(ca.timeout, func() {
+			if err := ca.send(answer, true); err != nil {
+				ca.errorHandler.handle("Failed to answer callback query:", err)
+			}
+		})
+	}
+
+	return answer, func() {
+		if timer != nil {
+			timer.Stop()
+		}
+
+		ca.mu.Lock()
+		delete(ca.pending[id], answer)
+
+		if len(ca.pending[id]) == 0 {
+			delete(ca.pending, id)
+		}
+		ca.mu.Unlock()
+	}
+}
+
+// send answers the callback query if it's not answered yet. It uses the separate context, since context of the
+// handler may be already done.
+func (ca *CallbackAnswerer) send(answer *CallbackAnswer, empty bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), callbackAnswerRequestTimeout)
+	defer cancel()
+
+	if err := answer.answer(ctx, empty); err != nil && !errors.Is(err, ErrCallbackQueryAnswered) {
+		return err
+	}
+
+	return nil
+}
+
+// reply returns the webhook reply with call of the ReplyHandler for answer.
+func (ca *CallbackAnswerer) reply(answer *CallbackAnswer, call *Call) *Call {
+	id := answer.params.CallbackQueryID
+
+	if call == nil {
+		params, ok := answer.take(false)
+		if !ok {
+			return nil
+		}
+
+		ca.answered(id)
+
+		return &Call{Method: MethodAnswerCallbackQuery, Payload: params}
+	}
+
+	if callbackQueryID(call) == id {
+		if _, ok := answer.take(false); !ok {
+			return nil
+		}
+
+		ca.answered(id)
+
+		return call
+	}
+
+	if err := ca.send(answer, false); err != nil {
+		ca.errorHandler.handle("Failed to answer callback query:", err)
+	}
+
+	return call
+}
+
+// intercept marks the pending answers as answered when the callback query is answered by the direct call.
+func (ca *CallbackAnswerer) intercept(ctx context.Context, call *Call, next Invoker) ([]byte, error) {
+	if call.Method == MethodAnswerCallbackQuery {
+		ca.answered(callbackQueryID(call))
+	}
+
+	return next(ctx, call)
+}
+
+// answered marks all pending answers to the callback query with id as answered.
+func (ca *CallbackAnswerer) answered(id string) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	for answer := range ca.pending[id] {
+		answer.mu.Lock()
+		answer.answered = true
+		answer.mu.Unlock()
+	}
+}
+
+// CallbackAnswerFromContext returns answer to the callback query handled under the CallbackAnswerer, or nil.
+func CallbackAnswerFromContext(ctx context.Context) *CallbackAnswer {
+	answer, _ := ctx.Value(callbackAnswerKey{}).(*CallbackAnswer)
+
+	return answer
+}
+
+// SetText sets the text of notification shown at the top of the chat screen.
+func (a *CallbackAnswer) SetText(text string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.params.Text, a.params.ShowAlert = text, false
+}
+
+// SetAlert sets the text of alert shown instead of notification.
+func (a *CallbackAnswer) SetAlert(text string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.params.Text, a.params.ShowAlert = text, true
+}
+
+// SetURL sets the URL which will be opened by the client, like game URL or t.me link with start parameter.
+func (a *CallbackAnswer) SetURL(url string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.params.URL = url
+}
+
+// SetCacheTime sets the maximum amount of time in seconds that the answer may be cached client-side.
+func (a *CallbackAnswer) SetCacheTime(seconds int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.params.CacheTime = seconds
+}
+
+// IsAnswered checks that the callback query is already answered.
+func (a *CallbackAnswer) IsAnswered() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.answered
+}
+
+// Answer immediately sends the answer, like for opening URL before long handling. Next calls return
+// ErrCallbackQueryAnswered.
+func (a *CallbackAnswer) Answer(ctx context.Context) error {
+	return a.answer(ctx, false)
+}
+
+// answer sends the answer once, empty answer ignores all its options.
+func (a *CallbackAnswer) answer(ctx context.Context, empty bool) error {
+	params, ok := a.take(empty)
+	if !ok {
+		return ErrCallbackQueryAnswered
+	}
+
+	_, err := a.bot.AnswerCallbackQueryContext(ctx, params)
+
+	return err
+}
+
+// take marks the answer as answered and returns its parameters, or false if it's already answered.
+func (a *CallbackAnswer) take(empty bool) (AnswerCallbackQuery, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.answered {
+		return AnswerCallbackQuery{}, false
+	}
+
+	// NOTE(toby3d): failed answer is not repeated, since Telegram may already accept it. The lock is released
+	// before the request, which passes through the interceptor of CallbackAnswerer.
+	a.answered = true
+
+	if empty {
+		return AnswerCallbackQuery{CallbackQueryID: a.params.CallbackQueryID}, true
+	}
+
+	return a.params, true
+}
+
+// callbackQueryID returns identifier of the callback query answered by call, if it's answerCallbackQuery.
+func callbackQueryID(call *Call) string {
+	if call.Method != MethodAnswerCallbackQuery {
+		return ""
+	}
+
+	switch p := call.Payload.(type) {
+	case AnswerCallbackQuery:
+		return p.CallbackQueryID
+	case *AnswerCallbackQuery:
+		if p != nil {
+			return p.CallbackQueryID
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,265 @@
+package telegram_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/toby3d/telegram/v5"
+	"gitlab.com/toby3d/telegram/v5/telegramtest"
+)
+
+func TestCallbackAnswerer(t *testing.T) {
+	errTest := errors.New("test")
+
+	for _, tc := range []struct {
+		name      string
+		handler   telegram.Handler
+		expError  error
+		expAnswer telegram.AnswerCallbackQuery
+	}{{
+		name:      "empty",
+		handler:   func(ctx context.Context, u *telegram.Update) error { return nil },
+		expAnswer: telegram.AnswerCallbackQuery{CallbackQueryID: "42"},
+	}, {
+		name: "alert",
+		handler: func(ctx context.Context, u *telegram.Update) error {
+			telegram.CallbackAnswerFromContext(ctx).SetAlert("Done!")
+
+			return nil
+		},
+		expAnswer: telegram.AnswerCallbackQuery{CallbackQueryID: "42", Text: "Done!", ShowAlert: true},
+	}, {
+		name: "error",
+		handler: func(ctx context.Context, u *telegram.Update) error {
+			telegram.CallbackAnswerFromContext(ctx).SetText("Failed")
+
+			return errTest
+		},
+		expError:  errTest,
+		expAnswer: telegram.AnswerCallbackQuery{CallbackQueryID: "42", Text: "Failed"},
+	}, {
+		name: "early",
+		handler: func(ctx context.Context, u *telegram.Update) error {
+			answer := telegram.CallbackAnswerFromContext(ctx)
+			answer.SetURL("https://t.me/toby3dBot?start=game")
+
+			if err := answer.Answer(ctx); err != nil {
+				return err
+			}
+
+			answer.SetText("ignored")
+			assert.True(t, answer.IsAnswered())
+
+			return answer.Answer(ctx)
+		},
+		expError:  telegram.ErrCallbackQueryAnswered,
+		expAnswer: telegram.AnswerCallbackQuery{CallbackQueryID: "42", URL: "https://t.me/toby3dBot?start=game"},
+	}, {
+		name: "timeout",
+		handler: func(ctx context.Context, u *telegram.Update) error {
+			time.Sleep(100 * time.Millisecond)
+			telegram.CallbackAnswerFromContext(ctx).SetText("too late")
+
+			return nil
+		},
+		expAnswer: telegram.AnswerCallbackQuery{CallbackQueryID: "42"},
+	}} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			srv := telegramtest.NewServer("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+			defer srv.Close()
+
+			bot, err := srv.NewBot()
+			assert.NoError(t, err)
+
+			ca := telegram.NewCallbackAnswerer(bot)
+			ca.SetTimeout(50 * time.Millisecond)
+
+			err = ca.Middleware(tc.handler)(context.Background(), &telegram.Update{
+				CallbackQuery: &telegram.CallbackQuery{ID: "42", Data: "vote"},
+			})
+			assert.True(t, errors.Is(err, tc.expError), err)
+			assert.Equal(t, []telegram.AnswerCallbackQuery{tc.expAnswer}, srv.CallbackAnswers())
+		})
+	}
+
+	t.Run("panic", func(t *testing.T) {
+		srv := telegramtest.NewServer("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+		defer srv.Close()
+
+		bot, err := srv.NewBot()
+		assert.NoError(t, err)
+
+		h := telegram.NewCallbackAnswerer(bot).Middleware(func(ctx context.Context, u *telegram.Update) error {
+			panic("oops")
+		})
+
+		assert.Panics(t, func() {
+			_ = h(context.Background(), &telegram.Update{CallbackQuery: &telegram.CallbackQuery{ID: "42"}})
+		})
+		assert.Equal(t, []telegram.AnswerCallbackQuery{{CallbackQueryID: "42"}}, srv.CallbackAnswers())
+	})
+	t.Run("direct", func(t *testing.T) {
+		srv := telegramtest.NewServer("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+		defer srv.Close()
+
+		bot, err := srv.NewBot()
+		assert.NoError(t, err)
+
+		h := telegram.NewCallbackAnswerer(bot).Middleware(func(ctx context.Context, u *telegram.Update) error {
+			_, err := bot.AnswerCallbackQuery(telegram.AnswerCallbackQuery{
+				CallbackQueryID: u.CallbackQuery.ID,
+				Text:            "Done!",
+			})
+			assert.True(t, telegram.CallbackAnswerFromContext(ctx).IsAnswered())
+
+			return err
+		})
+
+		assert.NoError(t, h(context.Background(), &telegram.Update{CallbackQuery: &telegram.CallbackQuery{ID: "42"}}))
+		assert.Equal(t, []telegram.AnswerCallbackQuery{{CallbackQueryID: "42", Text: "Done!"}}, srv.CallbackAnswers())
+	})
+	t.Run("canceled", func(t *testing.T) {
+		srv := telegramtest.NewServer("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+		defer srv.Close()
+
+		bot, err := srv.NewBot()
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		h := telegram.NewCallbackAnswerer(bot).Middleware(func(ctx context.Context, u *telegram.Update) error {
+			telegram.CallbackAnswerFromContext(ctx).SetText("Stopped")
+			cancel()
+
+			return nil
+		})
+
+		assert.NoError(t, h(ctx, &telegram.Update{CallbackQuery: &telegram.CallbackQuery{ID: "42"}}))
+		assert.Equal(t, []telegram.AnswerCallbackQuery{{CallbackQueryID: "42", Text: "Stopped"}},
+			srv.CallbackAnswers())
+	})
+	t.Run("redelivered", func(t *testing.T) {
+		srv := telegramtest.NewServer("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+		defer srv.Close()
+
+		bot, err := srv.NewBot()
+		assert.NoError(t, err)
+
+		ca := telegram.NewCallbackAnswerer(bot)
+		started, release := make(chan struct{}), make(chan struct{})
+		u := &telegram.Update{CallbackQuery: &telegram.CallbackQuery{ID: "42"}}
+
+		first := ca.Middleware(func(ctx context.Context, u *telegram.Update) error {
+			<-started
+
+			_, err := bot.AnswerCallbackQuery(telegram.AnswerCallbackQuery{
+				CallbackQueryID: u.CallbackQuery.ID,
+				Text:            "Done!",
+			})
+
+			return err
+		})
+		second := ca.Middleware(func(ctx context.Context, u *telegram.Update) error {
+			close(started)
+			<-release
+
+			return nil
+		})
+
+		errs := make(chan error, 1)
+
+		go func() { errs <- second(context.Background(), u) }()
+
+		assert.NoError(t, first(context.Background(), u))
+		close(release)
+		assert.NoError(t, <-errs)
+		assert.Equal(t, []telegram.AnswerCallbackQuery{{CallbackQueryID: "42", Text: "Done!"}}, srv.CallbackAnswers())
+	})
+	t.Run("other updates", func(t *testing.T) {
+		h := telegram.NewCallbackAnswerer(nil).Middleware(func(ctx context.Context, u *telegram.Update) error {
+			assert.Nil(t, telegram.CallbackAnswerFromContext(ctx))
+
+			return nil
+		})
+
+		assert.NoError(t, h(context.Background(), &telegram.Update{Message: &telegram.Message{Text: "hello"}}))
+	})
+}
+
+func TestCallbackAnswererReplyMiddleware(t *testing.T) {
+	message := &telegram.Call{Method: telegram.MethodSendMessage, Payload: telegram.NewMessage(telegram.ChatID{ID: 1},
+		"hello")}
+
+	for _, tc := range []struct {
+		name       string
+		handler    func(bot *telegram.Bot) telegram.ReplyHandler
+		expCall    *telegram.Call
+		expAnswers []telegram.AnswerCallbackQuery
+	}{{
+		name: "no call",
+		handler: func(*telegram.Bot) telegram.ReplyHandler {
+			return func(ctx context.Context, u *telegram.Update) *telegram.Call {
+				telegram.CallbackAnswerFromContext(ctx).SetText("Done!")
+
+				return nil
+			}
+		},
+		expCall: &telegram.Call{
+			Method:  telegram.MethodAnswerCallbackQuery,
+			Payload: telegram.AnswerCallbackQuery{CallbackQueryID: "42", Text: "Done!"},
+		},
+	}, {
+		name: "answer call",
+		handler: func(*telegram.Bot) telegram.ReplyHandler {
+			return func(ctx context.Context, u *telegram.Update) *telegram.Call {
+				return &telegram.Call{
+					Method:  telegram.MethodAnswerCallbackQuery,
+					Payload: &telegram.AnswerCallbackQuery{CallbackQueryID: "42", Text: "Done!"},
+				}
+			}
+		},
+		expCall: &telegram.Call{
+			Method:  telegram.MethodAnswerCallbackQuery,
+			Payload: &telegram.AnswerCallbackQuery{CallbackQueryID: "42", Text: "Done!"},
+		},
+	}, {
+		name: "answered",
+		handler: func(bot *telegram.Bot) telegram.ReplyHandler {
+			return func(ctx context.Context, u *telegram.Update) *telegram.Call {
+				_, _ = bot.AnswerCallbackQuery(telegram.AnswerCallbackQuery{CallbackQueryID: "42", Text: "Early"})
+
+				return &telegram.Call{
+					Method:  telegram.MethodAnswerCallbackQuery,
+					Payload: telegram.AnswerCallbackQuery{CallbackQueryID: "42", Text: "Done!"},
+				}
+			}
+		},
+		expAnswers: []telegram.AnswerCallbackQuery{{CallbackQueryID: "42", Text: "Early"}},
+	}, {
+		name: "other call",
+		handler: func(*telegram.Bot) telegram.ReplyHandler {
+			return func(ctx context.Context, u *telegram.Update) *telegram.Call { return message }
+		},
+		expCall:    message,
+		expAnswers: []telegram.AnswerCallbackQuery{{CallbackQueryID: "42"}},
+	}} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			srv := telegramtest.NewServer("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+			defer srv.Close()
+
+			bot, err := srv.NewBot()
+			assert.NoError(t, err)
+
+			h := telegram.NewCallbackAnswerer(bot).ReplyMiddleware(tc.handler(bot))
+
+			assert.Equal(t, tc.expCall, h(context.Background(), &telegram.Update{
+				CallbackQuery: &telegram.CallbackQuery{ID: "42"},
+			}))
+			assert.Equal(t, tc.expAnswers, srv.CallbackAnswers())
+		})
+	}
+}
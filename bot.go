@@ -2,12 +2,18 @@ package telegram
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
-	"log"
+	"io/ioutil"
 	"mime/multipart"
 	"net"
+	"net/textproto"
+	"net/url"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,117 +28,399 @@ type Bot struct {
 	AccessToken string
 	Updates     UpdatesChannel
 
-	client   *http.Client
-	marshler json.API
+	transport Transport
+	marshler  json.API
+	endpoint  Endpoint
+	limiter   *Limiter
+
+	migrateHandler MigrateHandler
+	interceptors   []Interceptor
+}
+
+// MigrateHandler is called when the group with from identifier is upgraded to the supergroup with to identifier. It
+// can be called several times for the same migration: after failed request to the old group and on each service
+// message about migration in updates.
+type MigrateHandler func(ctx context.Context, from, to int64)
+
+// Endpoint represents a Bot API server address. Use it for a self-hosted Bot API server, the Telegram test
+// environment or a local stand-in in tests.
+type Endpoint struct {
+	// Base URL of the Bot API server, DefaultEndpointURL by default.
+	URL string
+
+	// Base URL for downloading files, URL is used if empty.
+	FileURL string
+
+	// True, if requests must be sent into the Telegram test environment (/bot<token>/test/<method>).
+	Test bool
+
+	// True, if the server is running in the local mode, so GetFile returns an absolute path of the file on the
+	// server disk instead of a relative path for downloading.
+	Local bool
 }
 
+// DefaultEndpointURL is a base URL of the cloud Bot API server.
+const DefaultEndpointURL string = "https://api.telegram.org"
+
+var (
+	// ErrEmptyFilePath describes a try to get a file without access token or path.
+	ErrEmptyFilePath = errors.New("empty file path or access token")
+
+	// ErrEmptyFileName describes a try to upload an InputFile attachment without name.
+	ErrEmptyFileName = errors.New("empty name of uploaded file")
+)
+
 // New creates a new default Bot structure based on the input access token.
 func New(accessToken string) (b *Bot, err error) {
+	return NewWithEndpoint(accessToken, Endpoint{URL: DefaultEndpointURL})
+}
+
+// NewWithEndpoint creates a new Bot structure based on the input access token which sends all requests to the
+// provided Bot API server endpoint.
+//
+// Requests are sent by the NetHTTPTransport with the http.DefaultClient, so canceled context aborts them
+// immediately. Use SetClient for switch to the fasthttp.Client.
+func NewWithEndpoint(accessToken string, e Endpoint) (b *Bot, err error) {
 	b = new(Bot)
 	b.marshler = json.ConfigFastest
-	b.SetClient(&http.Client{})
+	b.SetTransport(NewNetHTTPTransport(nil))
+	b.SetEndpoint(e)
+	b.SetLimiter(NewLimiter())
 	b.AccessToken = accessToken
 	b.User, err = b.GetMe()
 
 	return b, err
 }
 
-// SetClient allow set custom fasthttp.Client (for proxy traffic, for example).
+// SetClient allow set custom fasthttp.Client (for proxy traffic, for example). Requests are sent by the
+// FastHTTPTransport after that.
 func (b *Bot) SetClient(newClient *http.Client) {
 	if b == nil {
 		b = new(Bot)
 	}
 
-	b.client = newClient
+	b.transport = NewFastHTTPTransport(newClient)
+}
+
+// SetTransport allow set custom Transport for requests to the Bot API server (NetHTTPTransport with custom
+// net/http.Client, for example).
+func (b *Bot) SetTransport(t Transport) {
+	b.transport = t
+}
+
+// SetEndpoint allow set custom Bot API server endpoint (for local Bot API server, for example).
+func (b *Bot) SetEndpoint(e Endpoint) {
+	b.endpoint = e
+}
+
+// SetLimiter allow set custom flood Limiter for outgoing messages. Use nil for disable limits.
+func (b *Bot) SetLimiter(l *Limiter) {
+	b.limiter = l
+}
+
+// SetMigrateHandler allow set handler of the group migrations to supergroups (for re-key stored chats data, for
+// example).
+func (b *Bot) SetMigrateHandler(h MigrateHandler) {
+	b.migrateHandler = h
 }
 
+// Endpoint returns current Bot API server endpoint.
+func (b Bot) Endpoint() Endpoint {
+	if b.endpoint.URL == "" {
+		b.endpoint.URL = DefaultEndpointURL
+	}
+
+	if b.endpoint.FileURL == "" {
+		b.endpoint.FileURL = b.endpoint.URL
+	}
+
+	return b.endpoint
+}
+
+// methodURL returns full URL of the Bot API method for current endpoint.
+func (b Bot) methodURL(method string) string {
+	e := b.Endpoint()
+
+	elems := []string{"bot" + b.AccessToken}
+	if e.Test {
+		elems = append(elems, "test")
+	}
+
+	return strings.TrimRight(e.URL, "/") + "/" + path.Join(append(elems, method)...)
+}
+
+// Do makes a request to the Bot API method with JSON-encoded payload and returns raw response body.
 func (b Bot) Do(method string, payload interface{}) ([]byte, error) {
-	u := http.AcquireURI()
-	defer http.ReleaseURI(u)
-	u.SetScheme("https")
-	u.SetHost("api.telegram.org")
-	u.SetPath(path.Join("bot"+b.AccessToken, method))
+	return b.DoContext(context.Background(), method, payload)
+}
 
+// DoContext is like Do but uses ctx for cancellation and deadlines.
+func (b Bot) DoContext(ctx context.Context, method string, payload interface{}) ([]byte, error) {
+	return b.invoke(ctx, &Call{Method: method, Payload: payload})
+}
+
+// do makes a JSON request to the Bot API method through the flood limiter and repeats it after the group migration.
+func (b Bot) do(ctx context.Context, method string, payload interface{}) ([]byte, error) {
 	var buf bytes.Buffer
 	if err := b.marshler.NewEncoder(&buf).Encode(payload); err != nil {
 		return nil, err
 	}
 
-	req := http.AcquireRequest()
-	defer http.ReleaseRequest(req)
-	req.Header.SetUserAgent("toby3d/telegram")
-	req.Header.SetMethod(http.MethodPost)
-	req.Header.SetContentType("application/json")
-	req.SetHostBytes(u.Host())
-	req.SetRequestURI(u.String())
-	req.SetBody(buf.Bytes())
+	chatID := b.marshler.Get(buf.Bytes(), "chat_id").ToString()
+	call := func(body []byte) func() ([]byte, error) {
+		return func() ([]byte, error) {
+			_, src, err := b.transport.Do(ctx, &TransportRequest{
+				Method:      http.MethodPost,
+				URL:         b.methodURL(method),
+				ContentType: "application/json",
+				Body:        bytes.NewReader(body),
+			})
+
+			return src, err
+		}
+	}
+
+	src, err := b.limit(ctx, method, chatID, true, call(buf.Bytes()))
+	if err != nil {
+		return nil, err
+	}
 
-	resp := http.AcquireResponse()
-	defer http.ReleaseResponse(resp)
+	newChatID := b.migrate(ctx, chatID, src)
+	if newChatID == 0 {
+		return src, nil
+	}
 
-	if err := b.client.Do(req, resp); err != nil {
+	body, err := replaceField(b.marshler, buf.Bytes(), "chat_id", strconv.AppendInt(nil, newChatID, 10))
+	if err != nil {
 		return nil, err
 	}
 
-	return resp.Body(), nil
+	return b.limit(ctx, method, strconv.FormatInt(newChatID, 10), true, call(body))
 }
 
+// Upload makes a multipart/form-data request to the Bot API method with payload fields and files attachments and
+// returns raw response body. Without any files it's equal to Do.
 func (b Bot) Upload(method string, payload map[string]string, files ...*InputFile) ([]byte, error) {
+	return b.UploadContext(context.Background(), method, payload, files...)
+}
+
+// UploadContext is like Upload but uses ctx for cancellation and deadlines.
+func (b Bot) UploadContext(ctx context.Context, method string, payload map[string]string,
+	files ...*InputFile) ([]byte, error) {
 	if len(files) == 0 {
-		return b.Do(method, payload)
+		return b.DoContext(ctx, method, payload)
 	}
 
-	body := new(bytes.Buffer)
-	w := multipart.NewWriter(body)
+	return b.invoke(ctx, &Call{Method: method, Payload: payload, Files: files})
+}
+
+// upload makes a multipart/form-data request to the Bot API method through the flood limiter and repeats it after
+// the group migration.
+func (b Bot) upload(ctx context.Context, method string, payload map[string]string,
+	files ...*InputFile) ([]byte, error) {
+	// NOTE(toby3d): request can be repeated only if all attachments can be read again from the same position.
+	offsets := make([]int64, len(files))
+	retryable := true
 
 	for i := range files {
-		_, fileName := filepath.Split(files[i].Attachment.Name())
+		s, ok := files[i].Attachment.(io.Seeker)
+		if !ok {
+			retryable = false
 
-		part, err := w.CreateFormFile(fileName, fileName)
-		if err != nil {
-			return nil, err
+			break
 		}
 
-		if _, err = io.Copy(part, files[i].Attachment); err != nil {
+		var err error
+		if offsets[i], err = s.Seek(0, io.SeekCurrent); err != nil {
 			return nil, err
 		}
 	}
 
-	for key, val := range payload {
-		if err := w.WriteField(key, val); err != nil {
-			return nil, err
+	call := func(payload map[string]string) func() ([]byte, error) {
+		return func() ([]byte, error) {
+			for i := range files {
+				if !retryable {
+					break
+				}
+
+				if _, err := files[i].Attachment.(io.Seeker).Seek(offsets[i], io.SeekStart); err != nil {
+					return nil, err
+				}
+			}
+
+			// NOTE(toby3d): multipart body is streamed through the pipe directly from files into the
+			// request, so memory usage does not depend on files size.
+			pr, pw := io.Pipe()
+			w := multipart.NewWriter(pipeWriter{pw})
+			written := make(chan struct{})
+
+			go func() {
+				defer close(written)
+
+				_ = pw.CloseWithError(writeMultipart(w, payload, files...))
+			}()
+
+			// NOTE(toby3d): transport can return before the whole body is read, so closed pipe stops the
+			// writer and attachments are not touched by it when the request is repeated.
+			defer func() {
+				_ = pr.Close()
+				<-written
+			}()
+
+			_, src, err := b.transport.Do(ctx, &TransportRequest{
+				Method:      http.MethodPost,
+				URL:         b.methodURL(method),
+				ContentType: w.FormDataContentType(),
+				Body:        pr,
+			})
+
+			return src, err
 		}
 	}
 
-	if err := w.Close(); err != nil {
+	src, err := b.limit(ctx, method, payload["chat_id"], retryable, call(payload))
+	if err != nil {
 		return nil, err
 	}
 
-	u := http.AcquireURI()
-	defer http.ReleaseURI(u)
-	u.SetScheme("https")
-	u.SetHost("api.telegram.org")
-	u.SetPath(path.Join("bot"+b.AccessToken, method))
+	newChatID := b.migrate(ctx, payload["chat_id"], src)
+	if newChatID == 0 || !retryable {
+		return src, nil
+	}
+
+	fields := make(map[string]string, len(payload))
+	for key, val := range payload {
+		fields[key] = val
+	}
+
+	fields["chat_id"] = strconv.FormatInt(newChatID, 10)
 
-	req := http.AcquireRequest()
-	defer http.ReleaseRequest(req)
-	req.Header.SetMethod(http.MethodPost)
-	req.SetRequestURIBytes(u.RequestURI())
-	req.Header.SetContentType(w.FormDataContentType())
-	req.Header.SetMultipartFormBoundary(w.Boundary())
+	return b.limit(ctx, method, fields["chat_id"], retryable, call(fields))
+}
 
-	if _, err := body.WriteTo(req.BodyWriter()); err != nil {
-		return nil, err
+// migrate checks that the response src is failed because the group with chatID was migrated to a supergroup, calls
+// the migration handler and returns identifier of the supergroup for repeat the request.
+func (b Bot) migrate(ctx context.Context, chatID string, src []byte) int64 {
+	to := b.marshler.Get(src, "parameters", "migrate_to_chat_id").ToInt64()
+	if to == 0 {
+		return 0
 	}
 
-	resp := http.AcquireResponse()
-	defer http.ReleaseResponse(resp)
+	from, err := strconv.ParseInt(chatID, 10, 64)
+	if err != nil || from == to {
+		return 0
+	}
 
-	if err := b.client.Do(req, resp); err != nil {
-		return nil, err
+	if b.migrateHandler != nil {
+		b.migrateHandler(ctx, from, to)
+	}
+
+	return to
+}
+
+// notifyMigration calls the migration handler if the update contains a service message about the group migration
+// to a supergroup.
+func (b Bot) notifyMigration(ctx context.Context, u *Update) {
+	if b.migrateHandler == nil || u == nil || u.Message == nil || u.Message.Chat == nil {
+		return
+	}
+
+	switch m := u.Message; {
+	case m.MigrateToChatID != 0:
+		b.migrateHandler(ctx, m.Chat.ID, m.MigrateToChatID)
+	case m.MigrateFromChatID != 0:
+		b.migrateHandler(ctx, m.MigrateFromChatID, m.Chat.ID)
+	}
+}
+
+// limit performs call through the flood Limiter, if it's set, and repeats call after flood control errors if it's
+// retryable.
+func (b Bot) limit(ctx context.Context, method, chatID string, retryable bool,
+	call func() ([]byte, error)) ([]byte, error) {
+	if b.limiter == nil {
+		return call()
+	}
+
+	for i := 0; ; i++ {
+		if isLimitedMethod(method) {
+			if err := b.limiter.Wait(ctx, chatID); err != nil {
+				return nil, err
+			}
+		}
+
+		src, err := call()
+		if err != nil {
+			return nil, err
+		}
+
+		retryAfter := time.Duration(b.marshler.Get(src, "parameters", "retry_after").ToInt()) * time.Second
+		if retryAfter <= 0 {
+			return src, nil
+		}
+
+		b.limiter.Block(chatID, retryAfter)
+
+		if !retryable || i >= b.limiter.MaxRetries {
+			return src, nil
+		}
+
+		if !isLimitedMethod(method) {
+			if err = sleep(ctx, retryAfter); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// pipeWriter skips empty writes, like of empty fields, since io.Pipe passes them to the reader as 0, nil result
+// which is rejected by fasthttp.
+type pipeWriter struct{ *io.PipeWriter }
+
+func (w pipeWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
 	}
 
-	return resp.Body(), nil
+	return w.PipeWriter.Write(p)
+}
+
+// writeMultipart writes files attachments and payload fields into w and closes it.
+func writeMultipart(w *multipart.Writer, payload map[string]string, files ...*InputFile) error {
+	for i := range files {
+		fileName := files[i].FileName()
+		if fileName == "" {
+			return ErrEmptyFileName
+		}
+
+		mimeType := files[i].MimeType
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(fileName), quoteEscaper.Replace(fileName)))
+		h.Set("Content-Type", mimeType)
+
+		part, err := w.CreatePart(h)
+		if err != nil {
+			return err
+		}
+
+		if _, err = io.Copy(part, files[i].Attachment); err != nil {
+			return err
+		}
+	}
+
+	for key, val := range payload {
+		if err := w.WriteField(key, val); err != nil {
+			return err
+		}
+	}
+
+	return w.Close()
 }
 
 // IsMessageFromMe checks that the input message is a message from the current bot.
@@ -178,17 +466,14 @@ func (b Bot) IsMessageMentionsMe(m Message) bool {
 		return true
 	}
 
-	var entities []*MessageEntity
-
-	switch {
-	case m.HasMentions():
-		entities = m.Entities
-	case m.HasCaptionMentions():
-		entities = m.CaptionEntities
+	for i, entity := range m.Entities {
+		if b.isMentionMe(entity, m.EntityText(i)) {
+			return true
+		}
 	}
 
-	for _, entity := range entities {
-		if entity.IsMention() && entity.User.ID == b.ID {
+	for i, entity := range m.CaptionEntities {
+		if b.isMentionMe(entity, m.CaptionEntityText(i)) {
 			return true
 		}
 	}
@@ -196,6 +481,20 @@ func (b Bot) IsMessageMentionsMe(m Message) bool {
 	return false
 }
 
+// isMentionMe checks that the entity with its text mentions the current bot.
+func (b Bot) isMentionMe(entity *MessageEntity, text string) bool {
+	switch {
+	case entity == nil:
+		return false
+	case entity.IsTextMention():
+		return entity.User != nil && entity.User.ID == b.ID
+	case entity.IsMention() && b.Username != "":
+		return strings.EqualFold(strings.TrimPrefix(text, "@"), b.Username)
+	default:
+		return false
+	}
+}
+
 // IsForwardMentionsMe checks that the input forwarded message mentions the current bot.
 func (b Bot) IsForwardMentionsMe(m Message) bool { return m.IsForward() && b.IsMessageMentionsMe(m) }
 
@@ -210,134 +509,143 @@ func (b Bot) IsMessageToMe(m Message) bool {
 		b.IsMessageMentionsMe(m))
 }
 
-// NewFileURL creates a fasthttp.URI to file with path getted from GetFile method.
+// NewFileURL creates a fasthttp.URI to file with path getted from GetFile method. For endpoint in the local mode
+// it returns an URI with the "file" scheme and absolute path on the server disk.
 func (b Bot) NewFileURL(filePath string) *http.URI {
-	if b.AccessToken == "" || filePath == "" {
+	u := b.FileURL(filePath)
+	if u == nil {
 		return nil
 	}
 
 	result := http.AcquireURI()
-	result.SetScheme("https")
-	result.SetHost("api.telegram.org")
-	result.SetPath(path.Join("file", "bot"+b.AccessToken, filePath))
+	result.Update(u.String())
+
+	return result
+}
+
+// FileURL is like NewFileURL but returns net/url.URL.
+func (b Bot) FileURL(filePath string) *url.URL {
+	if b.AccessToken == "" || filePath == "" {
+		return nil
+	}
+
+	e := b.Endpoint()
+
+	if e.Local && filepath.IsAbs(filePath) {
+		return &url.URL{Scheme: SchemeFile, Path: filepath.ToSlash(filePath)}
+	}
+
+	elems := []string{"file", "bot" + b.AccessToken}
+	if e.Test {
+		elems = append(elems, "test")
+	}
+
+	result, err := url.Parse(strings.TrimRight(e.FileURL, "/") + "/" + path.Join(append(elems, filePath)...))
+	if err != nil {
+		return nil
+	}
 
 	return result
 }
 
+// DownloadFile returns contents of the file with path getted from GetFile method. For endpoint in the local mode
+// the file is read directly from the disk.
+func (b Bot) DownloadFile(ctx context.Context, filePath string) ([]byte, error) {
+	u := b.FileURL(filePath)
+	if u == nil {
+		return nil, ErrEmptyFilePath
+	}
+
+	if u.Scheme == SchemeFile {
+		return ioutil.ReadFile(filepath.FromSlash(u.Path))
+	}
+
+	status, src, err := b.transport.Do(ctx, &TransportRequest{
+		Method: http.MethodGet,
+		URL:    u.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("cannot download file: %d %s", status, http.StatusMessage(status))
+	}
+
+	return src, nil
+}
+
 // NewRedirectURL creates new fasthttp.URI for redirecting from one chat to another.
 func (b Bot) NewRedirectURL(param string, group bool) *http.URI {
-	if b.User == nil || b.User.Username == "" {
+	u := b.RedirectURL(param, group)
+	if u == nil {
 		return nil
 	}
 
 	link := http.AcquireURI()
-	link.SetScheme("https")
-	link.SetHost("t.me")
-	link.SetPath(b.User.Username)
+	link.Update(u.String())
 
-	q := link.QueryArgs()
-	key := "start"
+	return link
+}
+
+// RedirectURL is like NewRedirectURL but returns net/url.URL.
+func (b Bot) RedirectURL(param string, group bool) *url.URL {
+	if b.User == nil || b.User.Username == "" {
+		return nil
+	}
 
+	key := "start"
 	if group {
 		key += "group"
 	}
 
+	q := make(url.Values)
 	q.Set(key, param)
-	link.SetQueryStringBytes(q.QueryString())
 
-	return link
+	return &url.URL{Scheme: "https", Host: "t.me", Path: "/" + b.User.Username, RawQuery: q.Encode()}
 }
 
 // NewLongPollingChannel creates channel for receive incoming updates using long polling.
 func (b *Bot) NewLongPollingChannel(params *GetUpdates) UpdatesChannel {
-	if params == nil {
-		params = &GetUpdates{
-			Offset:  0,
-			Limit:   100,
-			Timeout: 60,
-		}
-	}
-
-	b.Updates = make(UpdatesChannel, params.Limit)
+	return b.NewLongPollingChannelContext(context.Background(), params)
+}
 
-	go func() {
-		for {
-			updates, err := b.GetUpdates(params)
-			if err != nil {
-				dlog.Ln(err.Error())
-				dlog.Ln("Failed to get updates, retrying in 3 seconds...")
-				time.Sleep(time.Second * 3)
-				continue
-			}
+// NewLongPollingChannelContext is like NewLongPollingChannel but stops polling and closes the returned channel
+// after ctx is done. Use Poller directly for control over backoff, errors and offset confirmation.
+func (b *Bot) NewLongPollingChannelContext(ctx context.Context, params *GetUpdates) UpdatesChannel {
+	p := NewPoller(b, params)
+	updates, _ := p.Start()
 
-			for _, update := range updates {
-				if update.ID < params.Offset {
-					continue
-				}
+	// NOTE(toby3d): ctx which is never done, like context.Background, does not need the watcher.
+	if ctx.Done() == nil {
+		return updates
+	}
 
-				params.Offset = update.ID + 1
-				b.Updates <- update
-			}
-		}
+	go func() {
+		<-ctx.Done()
+		_ = p.Stop(context.Background())
 	}()
 
-	return b.Updates
+	return updates
 }
 
 // NewWebhookChannel creates channel for receive incoming updates via an outgoing webhook. Returns updates channel and
-// shutdown func.
+// shutdown func. If webhook can not be started, then returned channel is closed and shutdown func returns the error.
+// Later errors of the server are only printed in debug logs, use Webhook directly for control over errors and full
+// updates channel.
 //
 // If cert argument is provided by two strings (["path/to/cert.file", "path/to/cert.key"]), then TLS server will be
 // created by this filepaths.
 func (b *Bot) NewWebhookChannel(u *http.URI, p SetWebhook, ln net.Listener, crt ...string) (UpdatesChannel,
 	func() error) {
-	b.Updates = make(UpdatesChannel, 100) // NOTE(toby3d): channel size by default GetUpdates.Limit parameter
-	handleFunc := func(ctx *http.RequestCtx) {
-		dlog.Ln("Request path:", string(ctx.Path()))
-
-		if !bytes.HasPrefix(ctx.Path(), u.Path()) {
-			dlog.Ln("Unsupported request path:", string(ctx.Path()))
-
-			return
-		}
-
-		dlog.Ln("Catched supported request path:", string(ctx.Path()))
-
-		upd := new(Update)
-		if err := b.marshler.Unmarshal(ctx.Request.Body(), upd); err != nil {
-			return
-		}
+	w := NewWebhook(b, p)
+	w.path = append(w.path[:0], u.Path()...)
 
-		b.Updates <- upd
-	}
-
-	srv := http.Server{
-		Name:              b.Username,
-		Concurrency:       p.MaxConnections,
-		Handler:           handleFunc,
-		ReduceMemoryUsage: true,
-	}
-
-	go func() {
-		var err error
-
-		switch {
-		case len(crt) == 2:
-			dlog.Ln("Creating TLS router...")
-			err = srv.ServeTLS(ln, crt[0], crt[1])
-		default:
-			dlog.Ln("Creating simple router...")
-			err = srv.Serve(ln)
-		}
-
-		if err != nil {
-			log.Fatalln(err.Error())
-		}
-	}()
+	if _, err := w.Start(context.Background(), ln, crt...); err != nil {
+		dlog.Ln(err.Error())
 
-	if _, err := b.SetWebhook(p); err != nil {
-		log.Fatalln(err.Error())
+		return w.updates, func() error { return err }
 	}
 
-	return b.Updates, srv.Shutdown
+	return w.updates, func() error { return w.Stop(context.Background()) }
 }
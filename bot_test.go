@@ -0,0 +1,298 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	json "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	http "github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func TestBotDoContext(t *testing.T) {
+	t.Run("canceled", func(t *testing.T) {
+		b := Bot{AccessToken: "123:abc", marshler: json.ConfigFastest, transport: NewFastHTTPTransport(nil)}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		src, err := b.DoContext(ctx, MethodGetMe, nil)
+		assert.Nil(t, src)
+		assert.Equal(t, context.Canceled, err)
+	})
+}
+
+func TestBotNewFileURL(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		endpoint  Endpoint
+		filePath  string
+		expResult string
+	}{{
+		name:      "default",
+		filePath:  "photos/file_0.jpg",
+		expResult: "https://api.telegram.org/file/bot123:abc/photos/file_0.jpg",
+	}, {
+		name:      "custom",
+		endpoint:  Endpoint{URL: "http://localhost:8081", FileURL: "http://files.localhost"},
+		filePath:  "photos/file_0.jpg",
+		expResult: "http://files.localhost/file/bot123:abc/photos/file_0.jpg",
+	}, {
+		name:      "test",
+		endpoint:  Endpoint{Test: true},
+		filePath:  "photos/file_0.jpg",
+		expResult: "https://api.telegram.org/file/bot123:abc/test/photos/file_0.jpg",
+	}, {
+		name:      "local",
+		endpoint:  Endpoint{URL: "http://localhost:8081", Local: true},
+		filePath:  "/var/lib/telegram-bot-api/123:abc/photos/file_0.jpg",
+		expResult: "file:///var/lib/telegram-bot-api/123:abc/photos/file_0.jpg",
+	}} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			b := Bot{AccessToken: "123:abc"}
+			b.SetEndpoint(tc.endpoint)
+			assert.Equal(t, tc.expResult, b.NewFileURL(tc.filePath).String())
+		})
+	}
+}
+
+func TestBotMethodURL(t *testing.T) {
+	b := Bot{AccessToken: "123:abc"}
+	assert.Equal(t, "https://api.telegram.org/bot123:abc/getMe", b.methodURL(MethodGetMe))
+
+	b.SetEndpoint(Endpoint{URL: "http://localhost:8081/", Test: true})
+	assert.Equal(t, "http://localhost:8081/bot123:abc/test/getMe", b.methodURL(MethodGetMe))
+}
+
+func TestBotUploadContext(t *testing.T) {
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+
+	go http.Serve(ln, func(ctx *http.RequestCtx) {
+		fh, err := ctx.FormFile("upload.txt")
+		if err != nil {
+			ctx.SetStatusCode(http.StatusBadRequest)
+
+			return
+		}
+
+		ctx.SetBodyString(`{"ok":true,"result":{"file_id":"abc","file_size":` +
+			strconv.FormatInt(fh.Size, 10) + `,"file_path":"` + string(ctx.FormValue("user_id")) + `"}}`)
+	})
+
+	dir, err := ioutil.TempDir("", "telegram")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	f, err := os.Create(filepath.Join(dir, "upload.txt"))
+	assert.NoError(t, err)
+	defer f.Close()
+
+	_, err = f.Write(bytes.Repeat([]byte("a"), 1<<20))
+	assert.NoError(t, err)
+	_, err = f.Seek(0, io.SeekStart)
+	assert.NoError(t, err)
+
+	b := Bot{AccessToken: "123:abc", marshler: json.ConfigFastest, transport: NewFastHTTPTransport(&http.Client{
+		Dial: func(string) (net.Conn, error) { return ln.Dial() },
+	})}
+	b.SetEndpoint(Endpoint{URL: "http://telegram.test"})
+
+	src, err := b.UploadContext(context.Background(), MethodUploadStickerFile, map[string]string{"user_id": "42"},
+		&InputFile{Attachment: f})
+	assert.NoError(t, err)
+
+	result := new(File)
+	assert.NoError(t, parseResponseError(b.marshler, src, result))
+	assert.Equal(t, &File{FileID: "abc", FileSize: 1 << 20, FilePath: "42"}, result)
+}
+
+func TestBotUploadContextReader(t *testing.T) {
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+
+	go http.Serve(ln, func(ctx *http.RequestCtx) {
+		fh, err := ctx.FormFile("chart.png")
+		if err != nil {
+			ctx.SetStatusCode(http.StatusBadRequest)
+
+			return
+		}
+
+		ctx.SetBodyString(`{"ok":true,"result":{"file_id":"` + fh.Header.Get("Content-Type") + `"}}`)
+	})
+
+	b := Bot{AccessToken: "123:abc", marshler: json.ConfigFastest, transport: NewFastHTTPTransport(&http.Client{
+		Dial: func(string) (net.Conn, error) { return ln.Dial() },
+	})}
+	b.SetEndpoint(Endpoint{URL: "http://telegram.test"})
+
+	t.Run("valid", func(t *testing.T) {
+		src, err := b.UploadContext(context.Background(), MethodUploadStickerFile, nil, &InputFile{
+			Attachment: strings.NewReader("abc"),
+			Name:       "chart.png",
+			MimeType:   "image/png",
+		})
+		assert.NoError(t, err)
+
+		result := new(File)
+		assert.NoError(t, parseResponseError(b.marshler, src, result))
+		assert.Equal(t, "image/png", result.FileID)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := b.UploadContext(context.Background(), MethodUploadStickerFile, nil, &InputFile{
+			Attachment: strings.NewReader("abc"),
+		})
+		assert.Error(t, err)
+	})
+}
+
+type transportFunc func(ctx context.Context, req *TransportRequest) (int, []byte, error)
+
+func (f transportFunc) Do(ctx context.Context, req *TransportRequest) (int, []byte, error) {
+	return f(ctx, req)
+}
+
+func TestBotUploadContextRetry(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 1<<20)
+	calls := 0
+
+	b := Bot{AccessToken: "123:abc", marshler: json.ConfigFastest}
+	b.SetEndpoint(Endpoint{URL: "http://telegram.test"})
+	b.SetTransport(transportFunc(func(_ context.Context, req *TransportRequest) (int, []byte, error) {
+		if calls++; calls == 1 {
+			// NOTE(toby3d): response is returned before the whole body is read, as the server may do.
+			if _, err := io.CopyN(ioutil.Discard, req.Body, 1<<10); err != nil {
+				return 0, nil, err
+			}
+
+			return http.StatusBadRequest, []byte(`{"ok":false,"error_code":400,"description":"Bad Request: ` +
+				`group chat was upgraded to a supergroup chat","parameters":{"migrate_to_chat_id":-10042}}`), nil
+		}
+
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil || !bytes.Contains(body, content) || !bytes.Contains(body, []byte("-10042")) {
+			return http.StatusBadRequest, []byte(`{"ok":false,"error_code":400,"description":"Bad Request"}`), nil
+		}
+
+		return http.StatusOK, []byte(`{"ok":true,"result":{"file_id":"abc"}}`), nil
+	}))
+
+	src, err := b.UploadContext(context.Background(), MethodSendDocument, map[string]string{"chat_id": "-42"},
+		&InputFile{Attachment: bytes.NewReader(content), Name: "upload.txt"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	result := new(File)
+	assert.NoError(t, parseResponseError(b.marshler, src, result))
+	assert.Equal(t, "abc", result.FileID)
+}
+
+func TestBotMigrate(t *testing.T) {
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+
+	go http.Serve(ln, func(ctx *http.RequestCtx) {
+		chatID := json.Get(ctx.PostBody(), "chat_id").ToInt64()
+		if chatID == -42 {
+			ctx.SetStatusCode(http.StatusBadRequest)
+			ctx.SetBodyString(`{"ok":false,"error_code":400,"description":"Bad Request: group chat was ` +
+				`upgraded to a supergroup chat","parameters":{"migrate_to_chat_id":-10042}}`)
+
+			return
+		}
+
+		ctx.SetBodyString(`{"ok":true,"result":{"message_id":1,"chat":{"id":` + strconv.FormatInt(chatID, 10) +
+			`,"type":"supergroup"}}}`)
+	})
+
+	b := Bot{AccessToken: "123:abc", marshler: json.ConfigFastest, transport: NewFastHTTPTransport(&http.Client{
+		Dial: func(string) (net.Conn, error) { return ln.Dial() },
+	})}
+	b.SetEndpoint(Endpoint{URL: "http://telegram.test"})
+
+	var from, to int64
+
+	b.SetMigrateHandler(func(_ context.Context, oldChatID, newChatID int64) {
+		from, to = oldChatID, newChatID
+	})
+
+	result, err := b.SendMessage(NewMessage(ChatID{ID: -42}, "hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-10042), result.Chat.ID)
+	assert.Equal(t, int64(-42), from)
+	assert.Equal(t, int64(-10042), to)
+
+	t.Run("update", func(t *testing.T) {
+		from, to = 0, 0
+
+		b.notifyMigration(context.Background(), &Update{Message: &Message{
+			Chat:              &Chat{ID: -10024},
+			MigrateFromChatID: -24,
+		}})
+		assert.Equal(t, int64(-24), from)
+		assert.Equal(t, int64(-10024), to)
+	})
+}
+
+func TestBotRedirectURL(t *testing.T) {
+	b := Bot{User: &User{Username: "TestBot"}}
+	assert.Equal(t, "https://t.me/TestBot?start=abc", b.RedirectURL("abc", false).String())
+	assert.Equal(t, "https://t.me/TestBot?startgroup=abc", b.NewRedirectURL("abc", true).String())
+}
+
+func TestBotIsMessageMentionsMe(t *testing.T) {
+	b := Bot{User: &User{ID: 42, Username: "toby3dBot"}}
+
+	for _, tc := range []struct {
+		name      string
+		message   Message
+		expResult bool
+	}{{
+		name: "mention",
+		message: Message{Text: "👋 @toby3dbot", Chat: &Chat{Type: ChatGroup}, Entities: []*MessageEntity{
+			{Type: EntityMention, Offset: 3, Length: 10},
+		}},
+		expResult: true,
+	}, {
+		name: "caption mention",
+		message: Message{Caption: "🌍 @toby3dBot", Chat: &Chat{Type: ChatGroup}, CaptionEntities: []*MessageEntity{
+			{Type: EntityMention, Offset: 3, Length: 10},
+		}},
+		expResult: true,
+	}, {
+		name: "text mention",
+		message: Message{Text: "hello, bot", Chat: &Chat{Type: ChatGroup}, Entities: []*MessageEntity{
+			{Type: EntityTextMention, Offset: 7, Length: 3, User: &User{ID: 42}},
+		}},
+		expResult: true,
+	}, {
+		name: "other mention",
+		message: Message{Text: "👋 @toby3d", Chat: &Chat{Type: ChatGroup}, Entities: []*MessageEntity{
+			{Type: EntityMention, Offset: 3, Length: 7},
+			{Type: EntityTextMention, Offset: 0, Length: 2, User: &User{ID: 24}},
+		}},
+		expResult: false,
+	}, {
+		name: "command",
+		message: Message{Text: "/start@toby3dBot", Chat: &Chat{Type: ChatGroup}, Entities: []*MessageEntity{
+			{Type: EntityBotCommand, Length: 16},
+		}},
+		expResult: true,
+	}} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expResult, b.IsMessageMentionsMe(tc.message))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package telegram
+
+import "strings"
+
+// TextBuilder builds formatted text as plain Text and Entities with offsets in UTF-16 code units, which can be
+// used in SendMessage or captions without parse mode, so nothing must be escaped.
+//
+//	b := telegram.NewTextBuilder().Plain("Hello, ").Mention(user.FirstName, user).Plain("!")
+//	bot.SendMessage(telegram.SendMessage{ChatID: chatID, Text: b.Text(), Entities: b.Entities()})
+type TextBuilder struct {
+	text     strings.Builder
+	length   int
+	entities []*MessageEntity
+}
+
+// NewTextBuilder creates a new empty TextBuilder.
+func NewTextBuilder() *TextBuilder {
+	return new(TextBuilder)
+}
+
+// Plain appends text without formatting.
+func (b *TextBuilder) Plain(text string) *TextBuilder {
+	return b.append(text, nil)
+}
+
+// Bold appends bold text.
+func (b *TextBuilder) Bold(text string) *TextBuilder {
+	return b.append(text, &MessageEntity{Type: EntityBold})
+}
+
+// Italic appends italic text.
+func (b *TextBuilder) Italic(text string) *TextBuilder {
+	return b.append(text, &MessageEntity{Type: EntityItalic})
+}
+
+// Underline appends underlined text.
+func (b *TextBuilder) Underline(text string) *TextBuilder {
+	return b.append(text, &MessageEntity{Type: EntityUnderline})
+}
+
+// Strikethrough appends strikethrough text.
+func (b *TextBuilder) Strikethrough(text string) *TextBuilder {
+	return b.append(text, &MessageEntity{Type: EntityStrikethrough})
+}
+
+// Code appends monowidth string.
+func (b *TextBuilder) Code(text string) *TextBuilder {
+	return b.append(text, &MessageEntity{Type: EntityCode})
+}
+
+// Pre appends monowidth block with optional programming language of the code.
+func (b *TextBuilder) Pre(text, language string) *TextBuilder {
+	return b.append(text, &MessageEntity{Type: EntityPre, Language: language})
+}
+
+// Link appends text which opens url on click.
+func (b *TextBuilder) Link(text, url string) *TextBuilder {
+	return b.append(text, &MessageEntity{Type: EntityTextLink, URL: url})
+}
+
+// Mention appends text which mentions the user by ID, so users without username can be mentioned too.
+func (b *TextBuilder) Mention(text string, user *User) *TextBuilder {
+	return b.append(text, &MessageEntity{Type: EntityTextMention, User: user})
+}
+
+// Text returns built text.
+func (b *TextBuilder) Text() string {
+	return b.text.String()
+}
+
+// Entities returns entities of built text.
+func (b *TextBuilder) Entities() []*MessageEntity {
+	return b.entities
+}
+
+// Len returns length of built text in UTF-16 code units, as it's counted by Telegram for text limits.
+func (b *TextBuilder) Len() int {
+	return b.length
+}
+
+// append appends text with entity, which offset and length are set by text. Entities of empty texts are skipped,
+// since Telegram rejects them.
+func (b *TextBuilder) append(text string, entity *MessageEntity) *TextBuilder {
+	length := utf16Len(text)
+
+	if entity != nil && length > 0 {
+		entity.Offset, entity.Length = b.length, length
+		b.entities = append(b.entities, entity)
+	}
+
+	b.text.WriteString(text)
+	b.length += length
+
+	return b
+}
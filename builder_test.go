@@ -0,0 +1,40 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextBuilder(t *testing.T) {
+	user := &User{ID: 42, FirstName: "Maxim"}
+
+	b := NewTextBuilder().
+		Plain("👋 Hi, ").
+		Mention("Maxim", user).
+		Plain("!\n").
+		Bold("snake_case").
+		Italic("*").
+		Underline("").
+		Strikethrough("Привет").
+		Code("`x`").
+		Pre("fmt.Println(\"🚀\")", "go").
+		Link("site", "https://toby3d.me/")
+
+	assert.Equal(t, "👋 Hi, Maxim!\nsnake_case*Привет`x`fmt.Println(\"🚀\")site", b.Text())
+	assert.Equal(t, 55, b.Len())
+	assert.Equal(t, []*MessageEntity{
+		{Type: EntityTextMention, Offset: 7, Length: 5, User: user},
+		{Type: EntityBold, Offset: 14, Length: 10},
+		{Type: EntityItalic, Offset: 24, Length: 1},
+		{Type: EntityStrikethrough, Offset: 25, Length: 6},
+		{Type: EntityCode, Offset: 31, Length: 3},
+		{Type: EntityPre, Offset: 34, Length: 17, Language: "go"},
+		{Type: EntityTextLink, Offset: 51, Length: 4, URL: "https://toby3d.me/"},
+	}, b.Entities())
+
+	empty := NewTextBuilder()
+	assert.Empty(t, empty.Text())
+	assert.Empty(t, empty.Entities())
+	assert.Zero(t, empty.Len())
+}
@@ -0,0 +1,240 @@
+package telegram
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CallbackCodec encodes exported fields of structs into compact callback data of inline keyboard buttons and
+// decodes them back from callback queries.
+//
+// Fields are encoded in order of declaration and separated by colon, so the first string field, like action name,
+// can be matched by CallbackPrefix filter. Supported field types are strings, booleans and integers, fields with
+// `callback:"-"` tag are skipped.
+//
+// With the key, data is signed by truncated HMAC-SHA256, so forged data is rejected. With TTL, data contains the
+// time of encoding and is rejected after TTL.
+type CallbackCodec struct {
+	key []byte
+	ttl time.Duration
+}
+
+// MaxCallbackDataLength is the maximum length of callback data in bytes.
+const MaxCallbackDataLength int = 64
+
+const (
+	callbackSeparator string = ":"
+	callbackMACLength int    = 8
+)
+
+var (
+	// ErrCallbackDataTooLong describes encoded callback data which is longer than MaxCallbackDataLength.
+	ErrCallbackDataTooLong = errors.New("callback data is too long")
+
+	// ErrCallbackDataInvalid describes callback data which is malformed, does not match the decoded type or has
+	// invalid signature.
+	ErrCallbackDataInvalid = errors.New("invalid callback data")
+
+	// ErrCallbackDataExpired describes callback data encoded earlier than TTL of the CallbackCodec.
+	ErrCallbackDataExpired = errors.New("callback data is expired")
+)
+
+// NewCallbackCodec creates a new CallbackCodec which signs data by key. Empty key disables signing.
+func NewCallbackCodec(key []byte) *CallbackCodec {
+	return &CallbackCodec{key: key}
+}
+
+// SetTTL sets the duration after which encoded data is rejected. Zero disables expiration.
+func (c *CallbackCodec) SetTTL(ttl time.Duration) {
+	c.ttl = ttl
+}
+
+// Encode encodes fields of v, which must be a struct or a pointer to struct, into callback data.
+func (c *CallbackCodec) Encode(v interface{}) (string, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return "", fmt.Errorf("callback data: unsupported type %T", v)
+	}
+
+	parts := make([]string, 0, rv.NumField()+2)
+
+	for _, i := range callbackFields(rv.Type()) {
+		part, err := encodeCallbackField(rv.Field(i))
+		if err != nil {
+			return "", err
+		}
+
+		parts = append(parts, part)
+	}
+
+	if c.ttl > 0 {
+		parts = append(parts, strconv.FormatInt(time.Now().Unix(), 36))
+	}
+
+	data := strings.Join(parts, callbackSeparator)
+	if len(c.key) > 0 {
+		data += callbackSeparator + c.sign(data)
+	}
+
+	if len(data) > MaxCallbackDataLength {
+		return "", fmt.Errorf("%w: %d bytes of %d allowed", ErrCallbackDataTooLong, len(data),
+			MaxCallbackDataLength)
+	}
+
+	return data, nil
+}
+
+// Decode checks data and decodes it into v, which must be a pointer to struct of the same type as encoded.
+func (c *CallbackCodec) Decode(data string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("callback data: unsupported type %T", v)
+	}
+
+	rv = rv.Elem()
+
+	if len(c.key) > 0 {
+		i := strings.LastIndex(data, callbackSeparator)
+		if i < 0 || !hmac.Equal([]byte(data[i+1:]), []byte(c.sign(data[:i]))) {
+			return ErrCallbackDataInvalid
+		}
+
+		data = data[:i]
+	}
+
+	fields := callbackFields(rv.Type())
+	parts := strings.Split(data, callbackSeparator)
+
+	if c.ttl > 0 {
+		last := len(parts) - 1
+
+		timestamp, err := strconv.ParseInt(parts[last], 36, 64)
+		if err != nil {
+			return ErrCallbackDataInvalid
+		}
+
+		if time.Since(time.Unix(timestamp, 0)) > c.ttl {
+			return ErrCallbackDataExpired
+		}
+
+		parts = parts[:last]
+	}
+
+	if len(parts) != len(fields) {
+		return ErrCallbackDataInvalid
+	}
+
+	for i, part := range parts {
+		if err := decodeCallbackField(rv.Field(fields[i]), part); err != nil {
+			return ErrCallbackDataInvalid
+		}
+	}
+
+	return nil
+}
+
+// DecodeQuery decodes data of the callback query into v.
+func (c *CallbackCodec) DecodeQuery(q *CallbackQuery, v interface{}) error {
+	if q == nil {
+		return ErrCallbackDataInvalid
+	}
+
+	return c.Decode(q.Data, v)
+}
+
+// Button creates a new inline keyboard button with text and callback data encoded from v.
+func (c *CallbackCodec) Button(text string, v interface{}) (*InlineKeyboardButton, error) {
+	data, err := c.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewInlineKeyboardButton(text, data), nil
+}
+
+func (c *CallbackCodec) sign(data string) string {
+	h := hmac.New(sha256.New, c.key)
+	_, _ = h.Write([]byte(data))
+
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil)[:callbackMACLength])
+}
+
+// callbackFields returns indexes of encoded fields of struct type t.
+func callbackFields(t reflect.Type) []int {
+	fields := make([]int, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("callback") == "-" {
+			continue
+		}
+
+		fields = append(fields, i)
+	}
+
+	return fields
+}
+
+func encodeCallbackField(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.String:
+		// NOTE(toby3d): escape only separator and escape character itself to keep data compact.
+		return strings.NewReplacer("%", "%25", callbackSeparator, "%3A").Replace(v.String()), nil
+	case reflect.Bool:
+		if v.Bool() {
+			return "1", nil
+		}
+
+		return "", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 36), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 36), nil
+	default:
+		return "", fmt.Errorf("callback data: unsupported field type %s", v.Type())
+	}
+}
+
+func decodeCallbackField(v reflect.Value, src string) error {
+	switch v.Kind() {
+	case reflect.String:
+		s, err := url.PathUnescape(src)
+		if err != nil {
+			return err
+		}
+
+		v.SetString(s)
+	case reflect.Bool:
+		if src != "" && src != "1" {
+			return strconv.ErrSyntax
+		}
+
+		v.SetBool(src == "1")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(src, 36, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(src, 36, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetUint(i)
+	default:
+		return fmt.Errorf("callback data: unsupported field type %s", v.Type())
+	}
+
+	return nil
+}
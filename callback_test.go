@@ -0,0 +1,90 @@
+package telegram
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testCallback struct {
+	Action  string
+	ChatID  int64
+	Page    uint16
+	Confirm bool
+	Note    string `callback:"-"`
+	private string
+}
+
+func TestCallbackCodec(t *testing.T) {
+	value := testCallback{Action: "vote:up", ChatID: -1001234567890, Page: 42, Confirm: true}
+
+	t.Run("plain", func(t *testing.T) {
+		c := NewCallbackCodec(nil)
+
+		data, err := c.Encode(value)
+		assert.NoError(t, err)
+		assert.Equal(t, "vote%3Aup:-cryl7kya:16:1", data)
+
+		var result testCallback
+		assert.NoError(t, c.DecodeQuery(&CallbackQuery{Data: data}, &result))
+		assert.Equal(t, value, result)
+
+		assert.True(t, errors.Is(c.Decode("vote:1", &result), ErrCallbackDataInvalid), "fields count")
+		assert.True(t, errors.Is(c.Decode("vote:z!:1:", &result), ErrCallbackDataInvalid), "malformed")
+	})
+	t.Run("signed", func(t *testing.T) {
+		c := NewCallbackCodec([]byte("s3cr3t"))
+
+		button, err := c.Button("Vote", &value)
+		assert.NoError(t, err)
+		assert.Equal(t, "Vote", button.Text)
+		assert.True(t, CallbackPrefix("vote")(&Update{CallbackQuery: &CallbackQuery{Data: button.CallbackData}}))
+
+		var result testCallback
+		assert.NoError(t, c.Decode(button.CallbackData, &result))
+		assert.Equal(t, value, result)
+
+		forged := strings.Replace(button.CallbackData, ":16:", ":17:", 1)
+		assert.True(t, errors.Is(c.Decode(forged, &result), ErrCallbackDataInvalid))
+
+		plain, err := NewCallbackCodec(nil).Encode(value)
+		assert.NoError(t, err)
+		assert.True(t, errors.Is(c.Decode(plain, &result), ErrCallbackDataInvalid), "unsigned")
+		assert.True(t, errors.Is(NewCallbackCodec([]byte("other")).Decode(button.CallbackData, &result),
+			ErrCallbackDataInvalid), "other key")
+	})
+	t.Run("ttl", func(t *testing.T) {
+		c := NewCallbackCodec(nil)
+		c.SetTTL(time.Minute)
+
+		data, err := c.Encode(value)
+		assert.NoError(t, err)
+
+		var result testCallback
+		assert.NoError(t, c.Decode(data, &result))
+		assert.Equal(t, value, result)
+
+		stale := "vote:1:1:1:" + strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 36)
+		assert.True(t, errors.Is(c.Decode(stale, &result), ErrCallbackDataExpired))
+	})
+	t.Run("too long", func(t *testing.T) {
+		_, err := NewCallbackCodec([]byte("s3cr3t")).Encode(testCallback{Action: strings.Repeat("a", 50)})
+		assert.True(t, errors.Is(err, ErrCallbackDataTooLong))
+		assert.Contains(t, err.Error(), "64")
+	})
+	t.Run("unsupported", func(t *testing.T) {
+		c := NewCallbackCodec(nil)
+
+		_, err := c.Encode("data")
+		assert.Error(t, err)
+
+		_, err = c.Encode(struct{ Price float64 }{Price: 4.2})
+		assert.Error(t, err)
+
+		assert.Error(t, c.Decode("data", testCallback{}))
+	})
+}
@@ -164,6 +164,7 @@ const (
 // Scheme represents optional schemes for URLs
 const (
 	SchemeAttach   string = "attach"
+	SchemeFile     string = "file"
 	SchemeTelegram string = "tg"
 )
 
@@ -217,6 +218,9 @@ const (
 	UpdateInlineQuery        string = "inline_query"
 	UpdateMessage            string = "message"
 	UpdatePoll               string = "poll"
+	UpdatePollAnswer         string = "poll_answer"
+	UpdateMyChatMember       string = "my_chat_member"
+	UpdateChatMember         string = "chat_member"
 	UpdatePreCheckoutQuery   string = "pre_checkout_query"
 	UpdateShippingQuery      string = "shipping_query"
 )
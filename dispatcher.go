@@ -0,0 +1,213 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+type (
+	// Dispatcher handles updates by the pool of workers: updates from different chats are handled in parallel, but
+	// updates from the same chat (or the same user, if update has no chat) are always handled one by one in the
+	// order of receiving. Any free worker takes the next update, so a slow chat does not delay the others.
+	Dispatcher struct {
+		handler      Handler
+		workers      int
+		queueSize    int
+		errorHandler ErrorHandler
+	}
+
+	// PanicError describes the panic recovered in the handler.
+	PanicError struct {
+		// Value passed to panic
+		Value interface{}
+
+		// Stack trace of the panicked goroutine
+		Stack []byte
+	}
+
+	// dispatchQueue keeps updates of the Dispatcher: updates wait until the previous update with the same key is
+	// handled, then they are ready for any free worker.
+	dispatchQueue struct {
+		size    int
+		waiting map[uint64][]*Update
+		ready   []*Update
+	}
+)
+
+// DefaultQueueSize is the default number of updates from the same chat waiting in the Dispatcher queue.
+const DefaultQueueSize = 100
+
+// NewDispatcher creates a new Dispatcher which calls h for updates, like Router.HandleUpdate, with one worker per
+// CPU.
+func NewDispatcher(h Handler) *Dispatcher {
+	return &Dispatcher{
+		handler:   h,
+		workers:   runtime.NumCPU(),
+		queueSize: DefaultQueueSize,
+	}
+}
+
+// SetWorkers sets the number of updates handled in parallel.
+func (d *Dispatcher) SetWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+
+	d.workers = n
+}
+
+// SetQueueSize sets the number of updates from the same chat waiting while the previous one is handled. Listen
+// stops reading updates while the queue of the next update chat is full.
+func (d *Dispatcher) SetQueueSize(n int) {
+	if n < 0 {
+		n = 0
+	}
+
+	d.queueSize = n
+}
+
+// SetErrorHandler sets the callback for errors returned by handler and its panics recovered as PanicError, since
+// workers have no caller to return them to. Without the callback they are only printed in debug logs.
+func (d *Dispatcher) SetErrorHandler(h ErrorHandler) {
+	d.errorHandler = h
+}
+
+// Listen handles updates from channel until it will be closed or ctx is done. Already queued updates are handled
+// before return.
+func (d *Dispatcher) Listen(ctx context.Context, updates UpdatesChannel) {
+	var wg sync.WaitGroup
+
+	jobs := make(chan *Update)
+	handled := make(chan uint64)
+
+	for i := 0; i < d.workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for u := range jobs {
+				key := dispatchKey(u)
+				d.handle(ctx, u)
+				handled <- key
+			}
+		}()
+	}
+
+	defer func() {
+		close(jobs)
+		wg.Wait()
+	}()
+
+	q := &dispatchQueue{size: d.queueSize, waiting: make(map[uint64][]*Update)}
+	done := ctx.Done()
+
+	var held *Update // NOTE(toby3d): received update which queue is full
+
+	for updates != nil || len(q.waiting) > 0 {
+		var (
+			next   chan<- *Update
+			first  *Update
+			source UpdatesChannel
+		)
+
+		if len(q.ready) > 0 {
+			next, first = jobs, q.ready[0]
+		}
+
+		if held == nil {
+			source = updates
+		}
+
+		select {
+		case <-done:
+			done, updates, held = nil, nil, nil
+		case next <- first:
+			q.ready[0] = nil
+			q.ready = q.ready[1:]
+		case key := <-handled:
+			q.release(key)
+
+			if held != nil && q.push(held) {
+				held = nil
+			}
+		case u, ok := <-source:
+			if !ok {
+				updates = nil
+
+				continue
+			}
+
+			if !q.push(u) {
+				held = u
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) handle(ctx context.Context, u *Update) {
+	defer func() {
+		if r := recover(); r != nil {
+			d.errorHandler.handle("Failed to handle update:", PanicError{Value: r, Stack: debug.Stack()})
+		}
+	}()
+
+	if err := d.handler(ctx, u); err != nil {
+		d.errorHandler.handle("Failed to handle update:", err)
+	}
+}
+
+func (e PanicError) Error() string {
+	return fmt.Sprintf("handler panic: %v", e.Value)
+}
+
+// dispatchKey returns chat ID of the update, sender ID if there is no chat, or update ID if there is no sender.
+func dispatchKey(u *Update) uint64 {
+	if c := updateChat(u); c != nil {
+		return uint64(c.ID)
+	}
+
+	if user := updateUser(u); user != nil {
+		return uint64(user.ID)
+	}
+
+	return uint64(u.ID)
+}
+
+// push adds u to the ready updates if there is no update with the same key in progress, otherwise to the waiting
+// ones. It returns false if the waiting updates of the key are full.
+func (q *dispatchQueue) push(u *Update) bool {
+	key := dispatchKey(u)
+
+	queue, ok := q.waiting[key]
+	if !ok {
+		q.waiting[key] = nil
+		q.ready = append(q.ready, u)
+
+		return true
+	}
+
+	if len(queue) >= q.size {
+		return false
+	}
+
+	q.waiting[key] = append(queue, u)
+
+	return true
+}
+
+// release marks the update with key as handled and makes the next waiting update of the key ready.
+func (q *dispatchQueue) release(key uint64) {
+	queue := q.waiting[key]
+	if len(queue) == 0 {
+		delete(q.waiting, key)
+
+		return
+	}
+
+	q.ready = append(q.ready, queue[0])
+	q.waiting[key] = queue[1:]
+}
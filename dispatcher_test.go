@@ -0,0 +1,181 @@
+package telegram
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatcher(t *testing.T) {
+	t.Run("order", func(t *testing.T) {
+		var mu sync.Mutex
+
+		handled := make(map[int64][]int64)
+
+		d := NewDispatcher(func(ctx context.Context, u *Update) error {
+			time.Sleep(time.Duration(rand.Intn(100)) * time.Microsecond)
+			mu.Lock()
+			handled[u.Message.Chat.ID] = append(handled[u.Message.Chat.ID], u.ID)
+			mu.Unlock()
+
+			return nil
+		})
+		d.SetWorkers(4)
+		d.SetQueueSize(2)
+
+		updates := make(UpdatesChannel)
+
+		go func() {
+			for i := int64(0); i < 200; i++ {
+				updates <- &Update{ID: i, Message: &Message{Chat: &Chat{ID: i % 10}}}
+			}
+
+			close(updates)
+		}()
+
+		d.Listen(context.Background(), updates)
+
+		for chat := int64(0); chat < 10; chat++ {
+			expected := make([]int64, 0, 20)
+			for i := chat; i < 200; i += 10 {
+				expected = append(expected, i)
+			}
+
+			assert.Equal(t, expected, handled[chat])
+		}
+	})
+	t.Run("parallel", func(t *testing.T) {
+		var started sync.WaitGroup
+
+		started.Add(2)
+
+		d := NewDispatcher(func(ctx context.Context, u *Update) error {
+			started.Done()
+			started.Wait() // NOTE(toby3d): blocks forever if chats are not handled in parallel
+
+			return nil
+		})
+		d.SetWorkers(2)
+
+		updates := make(UpdatesChannel, 2)
+		updates <- &Update{CallbackQuery: &CallbackQuery{From: &User{ID: 1}}}
+		updates <- &Update{CallbackQuery: &CallbackQuery{From: &User{ID: 2}}}
+		close(updates)
+
+		done := make(chan struct{})
+
+		go func() {
+			d.Listen(context.Background(), updates)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("updates of different chats are not handled in parallel")
+		}
+	})
+	t.Run("slow chat", func(t *testing.T) {
+		release := make(chan struct{})
+		handled := make(chan int64, 20)
+
+		d := NewDispatcher(func(ctx context.Context, u *Update) error {
+			if u.Message.Chat.ID == 0 {
+				<-release
+			}
+
+			handled <- u.ID
+
+			return nil
+		})
+		d.SetWorkers(2)
+		d.SetQueueSize(3)
+
+		updates := make(UpdatesChannel)
+		done := make(chan struct{})
+
+		go func() {
+			d.Listen(context.Background(), updates)
+			close(done)
+		}()
+
+		// NOTE(toby3d): chats 0 and 2 share the worker with hash distribution of updates
+		for i := int64(0); i < 12; i++ {
+			select {
+			case updates <- &Update{ID: i, Message: &Message{Chat: &Chat{ID: i % 3}}}:
+			case <-time.After(time.Second):
+				t.Fatal("updates of other chats are blocked by the slow chat")
+			}
+		}
+
+		for i := 0; i < 8; i++ {
+			select {
+			case id := <-handled:
+				assert.NotZero(t, id%3)
+			case <-time.After(time.Second):
+				t.Fatal("updates of other chats are blocked by the slow chat")
+			}
+		}
+
+		close(release)
+		close(updates)
+		<-done
+
+		assert.Len(t, handled, 4)
+	})
+	t.Run("panic", func(t *testing.T) {
+		var (
+			mu      sync.Mutex
+			errs    []error
+			handled []int64
+		)
+
+		d := NewDispatcher(func(ctx context.Context, u *Update) error {
+			if u.ID == 1 {
+				panic("oops")
+			}
+
+			mu.Lock()
+			handled = append(handled, u.ID)
+			mu.Unlock()
+
+			return nil
+		})
+		d.SetWorkers(1)
+		d.SetErrorHandler(func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		})
+
+		updates := make(UpdatesChannel, 3)
+		for i := int64(0); i < 3; i++ {
+			updates <- &Update{ID: i, Message: &Message{Chat: &Chat{ID: 42}}}
+		}
+		close(updates)
+
+		d.Listen(context.Background(), updates)
+
+		assert.Equal(t, []int64{0, 2}, handled)
+
+		if assert.Len(t, errs, 1) {
+			err, ok := errs[0].(PanicError)
+			if assert.True(t, ok) {
+				assert.Equal(t, "oops", err.Value)
+				assert.NotEmpty(t, err.Stack)
+				assert.EqualError(t, err, "handler panic: oops")
+			}
+		}
+	})
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		d := NewDispatcher(func(ctx context.Context, u *Update) error { return nil })
+		d.Listen(ctx, make(UpdatesChannel))
+	})
+}
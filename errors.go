@@ -1,18 +1,54 @@
 package telegram
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"golang.org/x/xerrors"
 )
 
+// Error represents an unsuccessful response of the Bot API method.
 type Error struct {
-	Code        int                   `json:"error_code"`
-	Description string                `json:"description"`
-	Parameters  []*ResponseParameters `json:"parameters,omitempty"`
+	Code        int                 `json:"error_code"`
+	Description string              `json:"description"`
+	Parameters  *ResponseParameters `json:"parameters,omitempty"`
 	frame       xerrors.Frame
 }
 
+// Common Bot API errors which can be checked by errors.Is (or xerrors.Is) on Error returned by any method.
+var (
+	// ErrBlocked describes a try to send something to the user which blocked the bot.
+	ErrBlocked = errors.New("bot was blocked by the user")
+
+	// ErrKicked describes a try to send something to the group, supergroup or channel from which the bot was
+	// kicked.
+	ErrKicked = errors.New("bot was kicked from the chat")
+
+	// ErrUserDeactivated describes a try to send something to the deleted account.
+	ErrUserDeactivated = errors.New("user is deactivated")
+
+	// ErrChatNotFound describes a try to use unknown or inaccessible chat.
+	ErrChatNotFound = errors.New("chat not found")
+
+	// ErrNotModified describes a try to edit message without any changes in it.
+	ErrNotModified = errors.New("message is not modified")
+
+	// ErrMessageToEditNotFound describes a try to edit deleted or unknown message.
+	ErrMessageToEditNotFound = errors.New("message to edit not found")
+
+	// ErrMigrated describes a try to use the group which was upgraded to a supergroup. Use MigrateToChatID of
+	// the Error for repeat request.
+	ErrMigrated = errors.New("group chat was upgraded to a supergroup chat")
+
+	// ErrFloodWait describes a flood control error. Use RetryAfter of the Error for repeat request.
+	ErrFloodWait = errors.New("too many requests")
+
+	// ErrBadEntities describes a try to send text with invalid markup or message entities.
+	ErrBadEntities = errors.New("can't parse entities")
+)
+
 func (e Error) FormatError(p xerrors.Printer) error {
 	p.Printf("%d %s", e.Code, e.Description)
 	e.frame.Format(p)
@@ -27,3 +63,52 @@ func (e Error) Format(s fmt.State, r rune) {
 func (e Error) Error() string {
 	return fmt.Sprint(e)
 }
+
+// Is checks that the current error is the one of the common Bot API errors.
+func (e Error) Is(target error) bool {
+	switch target {
+	case ErrBlocked:
+		return e.Code == 403 && e.hasDescription("bot was blocked by the user")
+	case ErrKicked:
+		return e.Code == 403 && (e.hasDescription("bot was kicked from") ||
+			e.hasDescription("bot is not a member of"))
+	case ErrUserDeactivated:
+		return e.Code == 403 && e.hasDescription("user is deactivated")
+	case ErrChatNotFound:
+		return e.Code == 400 && e.hasDescription("chat not found")
+	case ErrNotModified:
+		return e.Code == 400 && e.hasDescription("message is not modified")
+	case ErrMessageToEditNotFound:
+		return e.Code == 400 && e.hasDescription("message to edit not found")
+	case ErrMigrated:
+		return e.MigrateToChatID() != 0
+	case ErrFloodWait:
+		return e.Code == 429 || e.RetryAfter() > 0
+	case ErrBadEntities:
+		return e.Code == 400 && e.hasDescription("can't parse entities")
+	default:
+		return false
+	}
+}
+
+// RetryAfter returns delay before the request can be repeated after the flood control error.
+func (e Error) RetryAfter() time.Duration {
+	if e.Parameters == nil {
+		return 0
+	}
+
+	return time.Duration(e.Parameters.RetryAfter) * time.Second
+}
+
+// MigrateToChatID returns identifier of the supergroup to which the group has been migrated.
+func (e Error) MigrateToChatID() int64 {
+	if e.Parameters == nil {
+		return 0
+	}
+
+	return e.Parameters.MigrateToChatID
+}
+
+func (e Error) hasDescription(substr string) bool {
+	return strings.Contains(strings.ToLower(e.Description), substr)
+}
@@ -0,0 +1,85 @@
+package telegram
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	json "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorIs(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		src    string
+		expErr error
+	}{{
+		name:   "blocked",
+		src:    `{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`,
+		expErr: ErrBlocked,
+	}, {
+		name:   "kicked",
+		src:    `{"ok":false,"error_code":403,"description":"Forbidden: bot was kicked from the supergroup chat"}`,
+		expErr: ErrKicked,
+	}, {
+		name:   "deactivated",
+		src:    `{"ok":false,"error_code":403,"description":"Forbidden: user is deactivated"}`,
+		expErr: ErrUserDeactivated,
+	}, {
+		name:   "chat not found",
+		src:    `{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`,
+		expErr: ErrChatNotFound,
+	}, {
+		name: "not modified",
+		src: `{"ok":false,"error_code":400,"description":"Bad Request: message is not modified: specified new ` +
+			`message content and reply markup are exactly the same as a current content and reply markup of ` +
+			`the message"}`,
+		expErr: ErrNotModified,
+	}, {
+		name:   "message to edit not found",
+		src:    `{"ok":false,"error_code":400,"description":"Bad Request: message to edit not found"}`,
+		expErr: ErrMessageToEditNotFound,
+	}, {
+		name: "migrated",
+		src: `{"ok":false,"error_code":400,"description":"Bad Request: group chat was upgraded to a supergroup ` +
+			`chat","parameters":{"migrate_to_chat_id":-1001234567890}}`,
+		expErr: ErrMigrated,
+	}, {
+		name: "flood wait",
+		src: `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 5",` +
+			`"parameters":{"retry_after":5}}`,
+		expErr: ErrFloodWait,
+	}, {
+		name: "bad entities",
+		src: `{"ok":false,"error_code":400,"description":"Bad Request: can't parse entities: Can't find end of ` +
+			`the entity starting at byte offset 4"}`,
+		expErr: ErrBadEntities,
+	}} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := parseResponseError(json.ConfigFastest, []byte(tc.src), new(Message))
+			assert.True(t, errors.Is(err, tc.expErr))
+
+			for _, target := range []error{
+				ErrBlocked, ErrKicked, ErrUserDeactivated, ErrChatNotFound, ErrNotModified,
+				ErrMessageToEditNotFound, ErrMigrated, ErrFloodWait, ErrBadEntities,
+			} {
+				if target != tc.expErr {
+					assert.False(t, errors.Is(err, target), target.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestErrorAs(t *testing.T) {
+	err := parseResponseError(json.ConfigFastest, []byte(`{"ok":false,"error_code":429,`+
+		`"description":"Too Many Requests: retry after 5","parameters":{"retry_after":5}}`), new(Message))
+
+	var tgErr *Error
+	assert.True(t, errors.As(err, &tgErr))
+	assert.Equal(t, 429, tgErr.Code)
+	assert.Equal(t, 5*time.Second, tgErr.RetryAfter())
+	assert.Zero(t, tgErr.MigrateToChatID())
+}
@@ -0,0 +1,70 @@
+package telegram_test
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/fasthttp/router"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/toby3d/telegram/v5"
+)
+
+func ExampleWebhook_ServeHTTP() {
+	bot, err := telegram.New("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	w := telegram.NewWebhook(bot, telegram.SetWebhook{
+		URL:         "https://example.site/webhook",
+		SecretToken: "s3cr3t",
+	})
+
+	// Share one port between webhook and other handlers.
+	mux := http.NewServeMux()
+	mux.Handle("/webhook", w)
+	mux.HandleFunc("/healthz", func(rw http.ResponseWriter, r *http.Request) { rw.WriteHeader(http.StatusNoContent) })
+
+	go func() {
+		if err := http.ListenAndServe(":8080", mux); err != nil {
+			log.Fatalln(err.Error())
+		}
+	}()
+
+	updates, err := w.Open(context.Background())
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	for update := range updates {
+		log.Println(update.ID)
+	}
+}
+
+func ExampleWebhook_HandleFastHTTP() {
+	bot, err := telegram.New("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	w := telegram.NewWebhook(bot, telegram.SetWebhook{URL: "https://example.site/webhook"})
+
+	r := router.New()
+	r.POST("/webhook", w.HandleFastHTTP)
+
+	go func() {
+		if err := fasthttp.ListenAndServe(":8080", r.Handler); err != nil {
+			log.Fatalln(err.Error())
+		}
+	}()
+
+	updates, err := w.Open(context.Background())
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	for update := range updates {
+		log.Println(update.ID)
+	}
+}
@@ -0,0 +1,189 @@
+package telegram
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Filter checks that the update must be handled by the route of Router.
+type Filter func(u *Update) bool
+
+// And creates a Filter which passes updates passed by all of filters.
+func And(filters ...Filter) Filter {
+	return func(u *Update) bool {
+		for _, f := range filters {
+			if !f(u) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// Or creates a Filter which passes updates passed by any of filters.
+func Or(filters ...Filter) Filter {
+	return func(u *Update) bool {
+		for _, f := range filters {
+			if f(u) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Not creates a Filter which passes updates rejected by f.
+func Not(f Filter) Filter {
+	return func(u *Update) bool { return !f(u) }
+}
+
+// MessageFilter creates a Filter from the Message predicate, like Message.IsReply or Bot.IsCommandToMe, which is
+// checked on the message of update (including message of the callback query).
+func MessageFilter(predicate func(m Message) bool) Filter {
+	return func(u *Update) bool {
+		m := updateMessage(u)
+
+		return m != nil && predicate(*m)
+	}
+}
+
+// Command creates a Filter which passes messages with any of bot commands (without leading slash), addressed to
+// any bot. Use CommandToMe in groups.
+func Command(commands ...string) Filter {
+	return MessageFilter(func(m Message) bool {
+		for _, command := range commands {
+			if m.IsCommandEqual(command) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// CommandToMe is like Command but also rejects commands addressed to other bots, like /start@OtherBot, which are
+// received in groups with disabled privacy mode. Commands without bot username are passed.
+func CommandToMe(b *Bot, commands ...string) Filter {
+	return And(Command(commands...), MessageFilter(func(m Message) bool {
+		parts := strings.SplitN(m.RawCommand(), "@", 2)
+
+		return len(parts) == 1 || (b.User != nil && strings.EqualFold(parts[1], b.Username))
+	}))
+}
+
+// Regexp creates a Filter which passes messages with text (or caption) and inline queries matched by re.
+func Regexp(re *regexp.Regexp) Filter {
+	return func(u *Update) bool {
+		switch {
+		case u.InlineQuery != nil:
+			return re.MatchString(u.InlineQuery.Query)
+		case u.ChosenInlineResult != nil:
+			return re.MatchString(u.ChosenInlineResult.Query)
+		}
+
+		m := updateMessage(u)
+		if m == nil || u.CallbackQuery != nil {
+			return false
+		}
+
+		if m.Text != "" {
+			return re.MatchString(m.Text)
+		}
+
+		return m.Caption != "" && re.MatchString(m.Caption)
+	}
+}
+
+// CallbackPrefix creates a Filter which passes callback queries with data starting with prefix.
+func CallbackPrefix(prefix string) Filter {
+	return func(u *Update) bool {
+		return u.CallbackQuery != nil && strings.HasPrefix(u.CallbackQuery.Data, prefix)
+	}
+}
+
+// MediaGroup creates a Filter which passes messages of albums.
+func MediaGroup() Filter {
+	return MessageFilter(func(m Message) bool { return m.MediaGroupID != "" })
+}
+
+// ChatType creates a Filter which passes updates from chats of any of types, like ChatPrivate or ChatGroup.
+func ChatType(types ...string) Filter {
+	return func(u *Update) bool {
+		c := updateChat(u)
+		if c == nil {
+			return false
+		}
+
+		for _, t := range types {
+			if strings.EqualFold(c.Type, t) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// updateMessage returns message of any kind from the update or message with the callback button.
+func updateMessage(u *Update) *Message {
+	switch {
+	case u.Message != nil:
+		return u.Message
+	case u.EditedMessage != nil:
+		return u.EditedMessage
+	case u.ChannelPost != nil:
+		return u.ChannelPost
+	case u.EditedChannelPost != nil:
+		return u.EditedChannelPost
+	case u.CallbackQuery != nil:
+		return u.CallbackQuery.Message
+	default:
+		return nil
+	}
+}
+
+// updateChat returns chat where the update is happened, if it's available.
+func updateChat(u *Update) *Chat {
+	switch {
+	case u.MyChatMember != nil:
+		return u.MyChatMember.Chat
+	case u.ChatMember != nil:
+		return u.ChatMember.Chat
+	}
+
+	if m := updateMessage(u); m != nil {
+		return m.Chat
+	}
+
+	return nil
+}
+
+// updateUser returns sender of the update, if it's available.
+func updateUser(u *Update) *User {
+	switch {
+	case u.Message != nil:
+		return u.Message.From
+	case u.EditedMessage != nil:
+		return u.EditedMessage.From
+	case u.InlineQuery != nil:
+		return u.InlineQuery.From
+	case u.ChosenInlineResult != nil:
+		return u.ChosenInlineResult.From
+	case u.CallbackQuery != nil:
+		return u.CallbackQuery.From
+	case u.ShippingQuery != nil:
+		return u.ShippingQuery.From
+	case u.PreCheckoutQuery != nil:
+		return u.PreCheckoutQuery.From
+	case u.PollAnswer != nil:
+		return u.PollAnswer.User
+	case u.MyChatMember != nil:
+		return u.MyChatMember.From
+	case u.ChatMember != nil:
+		return u.ChatMember.From
+	default:
+		return nil
+	}
+}
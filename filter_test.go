@@ -0,0 +1,74 @@
+package telegram
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilters(t *testing.T) {
+	command := &Update{Message: &Message{
+		Text:     "/start@toby3dBot deep",
+		Chat:     &Chat{ID: -42, Type: ChatGroup},
+		Entities: []*MessageEntity{{Type: EntityBotCommand, Offset: 0, Length: 16}},
+	}}
+	text := &Update{EditedMessage: &Message{Text: "hello world", Chat: &Chat{ID: 42, Type: ChatPrivate}}}
+	caption := &Update{ChannelPost: &Message{Caption: "hello channel", Chat: &Chat{ID: -100, Type: ChatChannel}}}
+	callback := &Update{CallbackQuery: &CallbackQuery{
+		Data:    "vote:42",
+		Message: &Message{Text: "hello", Chat: &Chat{ID: 42, Type: ChatPrivate}},
+	}}
+	inline := &Update{InlineQuery: &InlineQuery{Query: "hello inline"}}
+	album := &Update{Message: &Message{MediaGroupID: "abc", Chat: &Chat{ID: 42, Type: ChatPrivate}}}
+	member := &Update{MyChatMember: &ChatMemberUpdated{Chat: &Chat{ID: -42, Type: ChatSuperGroup}}}
+
+	bot := Bot{User: &User{ID: 1, Username: "toby3dBot"}}
+	hello := Regexp(regexp.MustCompile(`^hello`))
+
+	for _, tc := range []struct {
+		name      string
+		filter    Filter
+		update    *Update
+		expResult bool
+	}{
+		{name: "command", filter: Command("help", "start"), update: command, expResult: true},
+		{name: "other command", filter: Command("help"), update: command, expResult: false},
+		{name: "not command", filter: Command("start"), update: text, expResult: false},
+		{name: "command with username", filter: CommandToMe(&bot, "start"), update: command, expResult: true},
+		{name: "command to other", filter: CommandToMe(&Bot{User: &User{Username: "otherBot"}}, "start"),
+			update: command},
+		{name: "command without username", filter: CommandToMe(&bot, "start"), update: &Update{Message: &Message{
+			Text:     "/start",
+			Entities: []*MessageEntity{{Type: EntityBotCommand, Offset: 0, Length: 6}},
+		}}, expResult: true},
+		{name: "command to me", filter: MessageFilter(bot.IsCommandToMe), update: command, expResult: true},
+		{name: "message predicate", filter: MessageFilter(Message.IsReply), update: text, expResult: false},
+		{name: "regexp text", filter: hello, update: text, expResult: true},
+		{name: "regexp caption", filter: hello, update: caption, expResult: true},
+		{name: "regexp inline", filter: hello, update: inline, expResult: true},
+		{name: "regexp skips callback", filter: hello, update: callback, expResult: false},
+		{name: "regexp mismatch", filter: hello, update: command, expResult: false},
+		{name: "callback prefix", filter: CallbackPrefix("vote:"), update: callback, expResult: true},
+		{name: "callback other prefix", filter: CallbackPrefix("page:"), update: callback, expResult: false},
+		{name: "callback prefix of message", filter: CallbackPrefix(""), update: text, expResult: false},
+		{name: "media group", filter: MediaGroup(), update: album, expResult: true},
+		{name: "not media group", filter: MediaGroup(), update: text, expResult: false},
+		{name: "chat type", filter: ChatType(ChatGroup, ChatSuperGroup), update: command, expResult: true},
+		{name: "chat type of callback", filter: ChatType(ChatPrivate), update: callback, expResult: true},
+		{name: "chat type of member", filter: ChatType(ChatSuperGroup), update: member, expResult: true},
+		{name: "chat type without chat", filter: ChatType(ChatPrivate), update: inline, expResult: false},
+		{name: "and", filter: And(Command("start"), ChatType(ChatGroup)), update: command, expResult: true},
+		{name: "and mismatch", filter: And(Command("start"), ChatType(ChatPrivate)), update: command},
+		{name: "or", filter: Or(Command("help"), ChatType(ChatGroup)), update: command, expResult: true},
+		{name: "or mismatch", filter: Or(Command("help"), ChatType(ChatPrivate)), update: command},
+		{name: "not", filter: Not(ChatType(ChatPrivate)), update: command, expResult: true},
+		{name: "empty and", filter: And(), update: text, expResult: true},
+		{name: "empty or", filter: Or(), update: text, expResult: false},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expResult, tc.filter(tc.update))
+		})
+	}
+}
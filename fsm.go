@@ -0,0 +1,225 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type (
+	// FSM drives multi-step conversations: it keeps the named state of each user in each chat in the StateStorage
+	// and dispatches updates to the handler of the current state.
+	FSM struct {
+		storage        StateStorage
+		handlers       map[string]ConversationHandler
+		timeout        time.Duration
+		cancelCommands []string
+		cancelHandler  Handler
+		timeoutHandler Handler
+	}
+
+	// Conversation represents the current step of the conversation passed to the ConversationHandler.
+	Conversation struct {
+		// Key of the conversation
+		Key StateKey
+
+		// Name of the current state
+		State string
+
+		// Values collected on the previous steps, changes are saved after successful handling
+		Data map[string]string
+
+		finished bool
+	}
+
+	// ConversationHandler handles the update in the current state of the conversation.
+	ConversationHandler func(ctx context.Context, c *Conversation, u *Update) error
+)
+
+var (
+	// ErrUnknownState describes the stored state without registered handler.
+	ErrUnknownState = errors.New("unknown conversation state")
+
+	// ErrCleanupNotSupported describes a try to clean up the StateStorage which does not implement StateCleaner.
+	ErrCleanupNotSupported = errors.New("state storage does not support cleanup")
+)
+
+// NewFSM creates a new FSM which keeps states in the storage. If storage is nil, then MemoryStorage is used.
+func NewFSM(storage StateStorage) *FSM {
+	if storage == nil {
+		storage = NewMemoryStorage()
+	}
+
+	return &FSM{
+		storage:  storage,
+		handlers: make(map[string]ConversationHandler),
+	}
+}
+
+// SetTimeout sets the duration of inactivity after which the conversation is dropped. Zero disables timeouts.
+//
+// Timeouts are checked lazily on the next update of the same user, so states of abandoned conversations stay in
+// the storage until Cleanup call.
+func (f *FSM) SetTimeout(d time.Duration) {
+	f.timeout = d
+}
+
+// SetCancelCommands sets bot commands (without leading slash) which drop the conversation in any state.
+func (f *FSM) SetCancelCommands(commands ...string) {
+	f.cancelCommands = commands
+}
+
+// SetCancelHandler sets the callback for updates which cancel the conversation.
+func (f *FSM) SetCancelHandler(h Handler) {
+	f.cancelHandler = h
+}
+
+// SetTimeoutHandler sets the callback for the first update after the conversation is expired, unless it's already
+// removed by Cleanup. This update is not handled by the state handler.
+func (f *FSM) SetTimeoutHandler(h Handler) {
+	f.timeoutHandler = h
+}
+
+// Handle registers handler for the named state.
+func (f *FSM) Handle(state string, h ConversationHandler) {
+	f.handlers[state] = h
+}
+
+// Start begins the conversation of key in the state with initial data, replacing the current one, if any.
+func (f *FSM) Start(ctx context.Context, key StateKey, state string, data map[string]string) error {
+	return f.storage.SetState(ctx, key, &State{Name: state, Data: data, Expires: f.expires()})
+}
+
+// Get returns the current state of the conversation of key or nil if there is no active conversation.
+func (f *FSM) Get(ctx context.Context, key StateKey) (*State, error) {
+	return f.storage.GetState(ctx, key)
+}
+
+// Finish drops the conversation of key.
+func (f *FSM) Finish(ctx context.Context, key StateKey) error {
+	return f.storage.DeleteState(ctx, key)
+}
+
+// Cleanup removes all expired conversations from the storage without calling the timeout handler. Call it
+// periodically, like by time.Ticker, if users can abandon conversations. It returns ErrCleanupNotSupported if the
+// storage does not implement StateCleaner.
+func (f *FSM) Cleanup(ctx context.Context) error {
+	c, ok := f.storage.(StateCleaner)
+	if !ok {
+		return ErrCleanupNotSupported
+	}
+
+	return c.DeleteExpiredStates(ctx, time.Now())
+}
+
+// Active creates a Filter which passes updates from users with active (or just expired) conversation. Use it with
+// Router.OnUpdate and HandleUpdate to route updates into conversations before other handlers.
+func (f *FSM) Active() Filter {
+	return func(u *Update) bool {
+		key, ok := UpdateStateKey(u)
+		if !ok {
+			return false
+		}
+
+		state, err := f.storage.GetState(context.Background(), key)
+
+		return err == nil && state != nil
+	}
+}
+
+// HandleUpdate dispatches u to the handler of the current state of the sender conversation. Updates without active
+// conversation are ignored. The state is saved only if the handler returns no error, so the step can be retried.
+func (f *FSM) HandleUpdate(ctx context.Context, u *Update) error {
+	key, ok := UpdateStateKey(u)
+	if !ok {
+		return nil
+	}
+
+	state, err := f.storage.GetState(ctx, key)
+	if err != nil || state == nil {
+		return err
+	}
+
+	if state.IsExpired(time.Now()) {
+		if err = f.storage.DeleteState(ctx, key); err != nil {
+			return err
+		}
+
+		return f.call(ctx, f.timeoutHandler, u)
+	}
+
+	if f.isCancel(u) {
+		if err = f.storage.DeleteState(ctx, key); err != nil {
+			return err
+		}
+
+		return f.call(ctx, f.cancelHandler, u)
+	}
+
+	h, ok := f.handlers[state.Name]
+	if !ok {
+		return ErrUnknownState
+	}
+
+	c := &Conversation{Key: key, State: state.Name, Data: state.Data}
+	if c.Data == nil {
+		c.Data = make(map[string]string)
+	}
+
+	if err = h(ctx, c, u); err != nil {
+		return err
+	}
+
+	if c.finished {
+		return f.storage.DeleteState(ctx, key)
+	}
+
+	return f.storage.SetState(ctx, key, &State{Name: c.State, Data: c.Data, Expires: f.expires()})
+}
+
+func (f *FSM) isCancel(u *Update) bool {
+	return len(f.cancelCommands) > 0 && Command(f.cancelCommands...)(u)
+}
+
+func (f *FSM) call(ctx context.Context, h Handler, u *Update) error {
+	if h == nil {
+		return nil
+	}
+
+	return h(ctx, u)
+}
+
+func (f *FSM) expires() time.Time {
+	if f.timeout <= 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(f.timeout)
+}
+
+// Transition moves the conversation into the named state after handling.
+func (c *Conversation) Transition(state string) {
+	c.State = state
+	c.finished = false
+}
+
+// Finish ends the conversation after handling.
+func (c *Conversation) Finish() {
+	c.finished = true
+}
+
+// UpdateStateKey returns the conversation key of the update sender. Updates without chat, like inline queries, are
+// keyed by user only.
+func UpdateStateKey(u *Update) (StateKey, bool) {
+	var key StateKey
+
+	if user := updateUser(u); user != nil {
+		key.UserID = user.ID
+	}
+
+	if chat := updateChat(u); chat != nil {
+		key.ChatID = chat.ID
+	}
+
+	return key, key.UserID != 0
+}
@@ -0,0 +1,165 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFSM(t *testing.T) {
+	ctx := context.Background()
+	key := StateKey{ChatID: 42, UserID: 24}
+	message := func(text string) *Update {
+		m := &Message{Text: text, Chat: &Chat{ID: key.ChatID}, From: &User{ID: key.UserID}}
+		if len(text) > 0 && text[0] == '/' {
+			m.Entities = []*MessageEntity{{Type: EntityBotCommand, Length: len(text)}}
+		}
+
+		return &Update{Message: m}
+	}
+	errTest := errors.New("test")
+
+	var results []map[string]string
+
+	f := NewFSM(nil)
+	f.SetCancelCommands("cancel")
+	f.SetCancelHandler(func(ctx context.Context, u *Update) error {
+		results = append(results, nil)
+
+		return nil
+	})
+	f.Handle("name", func(ctx context.Context, c *Conversation, u *Update) error {
+		if u.Message.Text == "" {
+			return errTest
+		}
+
+		c.Data["name"] = u.Message.Text
+		c.Transition("age")
+
+		return nil
+	})
+	f.Handle("age", func(ctx context.Context, c *Conversation, u *Update) error {
+		c.Data["age"] = u.Message.Text
+		results = append(results, c.Data)
+		c.Finish()
+
+		return nil
+	})
+
+	r := NewRouter()
+	r.OnUpdate(f.HandleUpdate, f.Active())
+	r.OnMessage(func(ctx context.Context, m *Message) error {
+		return f.Start(ctx, key, "name", nil)
+	}, Command("register"))
+
+	t.Run("dialog", func(t *testing.T) {
+		assert.NoError(t, r.HandleUpdate(ctx, message("hello")))
+		assert.Empty(t, results)
+
+		assert.NoError(t, r.HandleUpdate(ctx, message("/register")))
+		assert.Equal(t, errTest, r.HandleUpdate(ctx, message("")))
+
+		state, err := f.Get(ctx, key)
+		assert.NoError(t, err)
+		if assert.NotNil(t, state) {
+			assert.Equal(t, "name", state.Name, "state must not be changed on error")
+		}
+
+		assert.NoError(t, r.HandleUpdate(ctx, message("Toby")))
+		assert.NoError(t, r.HandleUpdate(ctx, message("42")))
+		assert.Equal(t, []map[string]string{{"name": "Toby", "age": "42"}}, results)
+
+		state, err = f.Get(ctx, key)
+		assert.NoError(t, err)
+		assert.Nil(t, state)
+	})
+	t.Run("cancel", func(t *testing.T) {
+		results = nil
+
+		assert.NoError(t, r.HandleUpdate(ctx, message("/register")))
+		assert.NoError(t, r.HandleUpdate(ctx, message("/cancel")))
+		assert.Equal(t, []map[string]string{nil}, results)
+
+		state, err := f.Get(ctx, key)
+		assert.NoError(t, err)
+		assert.Nil(t, state)
+	})
+	t.Run("timeout", func(t *testing.T) {
+		var expired []*Update
+
+		f.SetTimeout(10 * time.Millisecond)
+		f.SetTimeoutHandler(func(ctx context.Context, u *Update) error {
+			expired = append(expired, u)
+
+			return nil
+		})
+
+		u := message("Toby")
+
+		assert.NoError(t, r.HandleUpdate(ctx, message("/register")))
+		time.Sleep(20 * time.Millisecond)
+		assert.NoError(t, r.HandleUpdate(ctx, u))
+		assert.Equal(t, []*Update{u}, expired)
+
+		state, err := f.Get(ctx, key)
+		assert.NoError(t, err)
+		assert.Nil(t, state)
+	})
+	t.Run("cleanup", func(t *testing.T) {
+		other := StateKey{ChatID: 42, UserID: 42}
+
+		f.SetTimeout(10 * time.Millisecond)
+		assert.NoError(t, f.Start(ctx, key, "name", nil))
+		time.Sleep(20 * time.Millisecond)
+		assert.NoError(t, f.Start(ctx, other, "name", nil))
+		assert.NoError(t, f.Cleanup(ctx))
+
+		state, err := f.Get(ctx, key)
+		assert.NoError(t, err)
+		assert.Nil(t, state)
+
+		state, err = f.Get(ctx, other)
+		assert.NoError(t, err)
+		assert.NotNil(t, state)
+
+		assert.Equal(t, ErrCleanupNotSupported, NewFSM(struct{ StateStorage }{NewMemoryStorage()}).Cleanup(ctx))
+	})
+	t.Run("unknown", func(t *testing.T) {
+		assert.NoError(t, f.Start(ctx, key, "unknown", nil))
+		assert.Equal(t, ErrUnknownState, r.HandleUpdate(ctx, message("hello")))
+	})
+}
+
+func TestUpdateStateKey(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		update *Update
+		expKey StateKey
+		expOk  bool
+	}{{
+		name:   "message",
+		update: &Update{Message: &Message{Chat: &Chat{ID: 42}, From: &User{ID: 24}}},
+		expKey: StateKey{ChatID: 42, UserID: 24},
+		expOk:  true,
+	}, {
+		name:   "inline",
+		update: &Update{InlineQuery: &InlineQuery{From: &User{ID: 24}}},
+		expKey: StateKey{UserID: 24},
+		expOk:  true,
+	}, {
+		name:   "channel",
+		update: &Update{ChannelPost: &Message{Chat: &Chat{ID: 42}}},
+		expKey: StateKey{ChatID: 42},
+		expOk:  false,
+	}} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			key, ok := UpdateStateKey(tc.update)
+			assert.Equal(t, tc.expKey, key)
+			assert.Equal(t, tc.expOk, ok)
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package telegram
 
+import "context"
+
 type (
 	// Game represents a game. Use BotFather to create and edit games, their short names will act as unique
 	// identifiers.
@@ -113,7 +115,12 @@ func NewGame(chatID int64, gameShortName string) SendGame {
 
 // SendGame send a game. On success, the sent Message is returned.
 func (b Bot) SendGame(p SendGame) (*Message, error) {
-	src, err := b.Do(MethodSendGame, p)
+	return b.SendGameContext(context.Background(), p)
+}
+
+// SendGameContext is like SendGame but uses ctx for cancellation and deadlines.
+func (b Bot) SendGameContext(ctx context.Context, p SendGame) (*Message, error) {
+	src, err := b.DoContext(ctx, MethodSendGame, p)
 	if err != nil {
 		return nil, err
 	}
@@ -137,7 +144,12 @@ func NewGameScore(userID int64, score int) SetGameScore {
 // the edited Message, otherwise returns True. Returns an error, if the new score is not greater than the user's
 // current score in the chat and force is False.
 func (b Bot) SetGameScore(p SetGameScore) (*Message, error) {
-	src, err := b.Do(MethodSetGameScore, p)
+	return b.SetGameScoreContext(context.Background(), p)
+}
+
+// SetGameScoreContext is like SetGameScore but uses ctx for cancellation and deadlines.
+func (b Bot) SetGameScoreContext(ctx context.Context, p SetGameScore) (*Message, error) {
+	src, err := b.DoContext(ctx, MethodSetGameScore, p)
 	if err != nil {
 		return nil, err
 	}
@@ -153,7 +165,12 @@ func (b Bot) SetGameScore(p SetGameScore) (*Message, error) {
 // GetGameHighScores get data for high score tables. Will return the score of the specified user and several of his
 // neighbors in a game. On success, returns an Array of GameHighScore objects.
 func (b Bot) GetGameHighScores(p GetGameHighScores) ([]*GameHighScore, error) {
-	src, err := b.Do(MethodGetGameHighScores, p)
+	return b.GetGameHighScoresContext(context.Background(), p)
+}
+
+// GetGameHighScoresContext is like GetGameHighScores but uses ctx for cancellation and deadlines.
+func (b Bot) GetGameHighScoresContext(ctx context.Context, p GetGameHighScores) ([]*GameHighScore, error) {
+	src, err := b.DoContext(ctx, MethodGetGameHighScores, p)
 	if err != nil {
 		return nil, err
 	}
@@ -1,5 +1,7 @@
 package telegram
 
+import "context"
+
 type (
 	// InlineQuery represents an incoming inline query. When the user sends an empty query, your bot could return
 	// some default or trending results.
@@ -1026,7 +1028,12 @@ func NewAnswerInline(inlineQueryID string, results ...InlineQueryResult) AnswerI
 //
 // No more than 50 results per query are allowed.
 func (b Bot) AnswerInlineQuery(p AnswerInlineQuery) (ok bool, err error) {
-	src, err := b.Do(MethodAnswerInlineQuery, p)
+	return b.AnswerInlineQueryContext(context.Background(), p)
+}
+
+// AnswerInlineQueryContext is like AnswerInlineQuery but uses ctx for cancellation and deadlines.
+func (b Bot) AnswerInlineQueryContext(ctx context.Context, p AnswerInlineQuery) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodAnswerInlineQuery, p)
 	if err != nil {
 		return ok, err
 	}
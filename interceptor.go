@@ -0,0 +1,65 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+)
+
+type (
+	// Call represents a single Bot API request passed through the Interceptor chain.
+	Call struct {
+		// Name of the Bot API method
+		Method string
+
+		// Request payload. It's a map[string]string of form fields if Files is not empty, otherwise it's any
+		// value which will be encoded into JSON.
+		Payload interface{}
+
+		// Files attachments of the multipart/form-data request
+		Files []*InputFile
+	}
+
+	// Invoker performs the call and returns raw response body.
+	Invoker func(ctx context.Context, call *Call) ([]byte, error)
+
+	// Interceptor wraps every Bot API request. It can inspect or change the call before passing it to the next
+	// Invoker, inspect or change the raw response and error returned by it, or do not call next at all for
+	// short-circuit the request.
+	Interceptor func(ctx context.Context, call *Call, next Invoker) ([]byte, error)
+)
+
+// ErrInvalidPayload describes a call with files which payload is not a map[string]string of form fields.
+var ErrInvalidPayload = errors.New("payload of call with files must be a map[string]string")
+
+// Use appends interceptors into the chain around Do and Upload. The first added interceptor is the outermost one.
+func (b *Bot) Use(interceptors ...Interceptor) {
+	b.interceptors = append(b.interceptors, interceptors...)
+}
+
+// invoke passes the call through the interceptors chain to perform.
+func (b Bot) invoke(ctx context.Context, call *Call) ([]byte, error) {
+	next := Invoker(b.perform)
+
+	for i := len(b.interceptors) - 1; i >= 0; i-- {
+		interceptor, invoker := b.interceptors[i], next
+		next = func(ctx context.Context, call *Call) ([]byte, error) {
+			return interceptor(ctx, call, invoker)
+		}
+	}
+
+	return next(ctx, call)
+}
+
+// perform is the last Invoker in the chain which makes the real request.
+func (b Bot) perform(ctx context.Context, call *Call) ([]byte, error) {
+	if len(call.Files) == 0 {
+		return b.do(ctx, call.Method, call.Payload)
+	}
+
+	payload, ok := call.Payload.(map[string]string)
+	if !ok {
+		return nil, ErrInvalidPayload
+	}
+
+	return b.upload(ctx, call.Method, payload, call.Files...)
+}
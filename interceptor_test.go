@@ -0,0 +1,64 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	json "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBotUse(t *testing.T) {
+	t.Run("order", func(t *testing.T) {
+		var calls []string
+
+		b := Bot{marshler: json.ConfigFastest}
+		b.Use(func(ctx context.Context, call *Call, next Invoker) ([]byte, error) {
+			calls = append(calls, "first:"+call.Method)
+			src, err := next(ctx, call)
+			calls = append(calls, "first:"+string(src))
+
+			return src, err
+		}, func(ctx context.Context, call *Call, next Invoker) ([]byte, error) {
+			calls = append(calls, "second:"+call.Method)
+
+			return []byte(`{"ok":true,"result":true}`), nil
+		})
+
+		ok, err := b.DeleteWebhook(DeleteWebhook{})
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []string{
+			"first:" + MethodDeleteWebhook,
+			"second:" + MethodDeleteWebhook,
+			`first:{"ok":true,"result":true}`,
+		}, calls)
+	})
+	t.Run("change", func(t *testing.T) {
+		b := Bot{marshler: json.ConfigFastest}
+		b.Use(func(ctx context.Context, call *Call, next Invoker) ([]byte, error) {
+			call.Method = MethodGetMe
+
+			return next(ctx, call)
+		}, func(ctx context.Context, call *Call, next Invoker) ([]byte, error) {
+			assert.Equal(t, MethodGetMe, call.Method)
+
+			return nil, errors.New("fault")
+		})
+
+		_, err := b.GetWebhookInfo()
+		assert.EqualError(t, err, "fault")
+	})
+	t.Run("invalid", func(t *testing.T) {
+		b := Bot{marshler: json.ConfigFastest}
+		b.Use(func(ctx context.Context, call *Call, next Invoker) ([]byte, error) {
+			call.Payload = nil
+
+			return next(ctx, call)
+		})
+
+		_, err := b.Upload(MethodSendPhoto, map[string]string{}, &InputFile{Name: "photo.jpeg"})
+		assert.Equal(t, ErrInvalidPayload, err)
+	})
+}
@@ -0,0 +1,187 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limiter throttles outgoing messages according to the Telegram flood limits and repeats requests which are failed
+// with the 429 Too Many Requests error after the retry_after delay provided by server.
+//
+// See https://core.telegram.org/bots/faq#my-bot-is-hitting-limits-how-do-i-avoid-this
+type Limiter struct {
+	// Maximum number of repeats of the single request after a flood control error.
+	MaxRetries int
+
+	global  bucket
+	chat    bucket
+	group   bucket
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// bucket is a GCRA-based rate limit state: interval is a time between two requests and burst is a number of
+// requests which can be sent at once.
+type bucket struct {
+	interval time.Duration
+	burst    int
+	tat      time.Time // NOTE(toby3d): theoretical arrival time of the next request
+}
+
+const (
+	// LimitGlobal is a default number of messages per second for all chats.
+	LimitGlobal int = 30
+
+	// LimitChat is a default number of messages per second for a single chat.
+	LimitChat int = 1
+
+	// LimitGroup is a default number of messages per minute for a single group.
+	LimitGroup int = 20
+
+	// DefaultMaxRetries is a default number of repeats of the single request after a flood control error.
+	DefaultMaxRetries int = 3
+)
+
+// NewLimiter creates a new Limiter with default Telegram flood limits.
+func NewLimiter() *Limiter {
+	return NewLimiterWithLimits(LimitGlobal, LimitChat, LimitGroup)
+}
+
+// NewLimiterWithLimits creates a new Limiter with global messages per second, per chat messages per second and per
+// group messages per minute limits.
+func NewLimiterWithLimits(global, chat, group int) *Limiter {
+	return &Limiter{
+		MaxRetries: DefaultMaxRetries,
+		global:     bucket{interval: time.Second / time.Duration(global), burst: global},
+		chat:       bucket{interval: time.Second / time.Duration(chat), burst: chat},
+		group:      bucket{interval: time.Minute / time.Duration(group), burst: group},
+		buckets:    make(map[string]*bucket),
+	}
+}
+
+// Wait blocks until a new message can be sent into the chat with chatID (or into any chat, if chatID is empty) or
+// until ctx is done.
+func (l *Limiter) Wait(ctx context.Context, chatID string) error {
+	if l == nil {
+		return nil
+	}
+
+	delay := l.reserve(time.Now(), chatID)
+	if delay <= 0 {
+		return nil
+	}
+
+	return sleep(ctx, delay)
+}
+
+// Block postpones all next messages into the chat with chatID (or into any chat, if chatID is empty) for d
+// duration. It's called after the flood control error with retry_after delay provided by server.
+func (l *Limiter) Block(chatID string, d time.Duration) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := &l.global
+	if chatID != "" {
+		b = l.bucket(time.Now(), chatID)
+	}
+
+	b.block(time.Now().Add(d))
+}
+
+func (l *Limiter) reserve(now time.Time, chatID string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delay := l.global.reserve(now)
+
+	if chatID == "" {
+		return delay
+	}
+
+	if chatDelay := l.bucket(now, chatID).reserve(now); chatDelay > delay {
+		delay = chatDelay
+	}
+
+	return delay
+}
+
+// bucket returns limit state of the chat with chatID, creating it if necessary.
+func (l *Limiter) bucket(now time.Time, chatID string) *bucket {
+	if b, ok := l.buckets[chatID]; ok {
+		return b
+	}
+
+	// NOTE(toby3d): drop outdated states from time to time to avoid memory leak on a huge number of chats.
+	if len(l.buckets) > 1000 {
+		l.cleanup(now)
+	}
+
+	b := new(bucket)
+	*b = l.chat
+
+	// NOTE(toby3d): negative identifiers and usernames are belongs to groups, supergroups and channels.
+	if strings.HasPrefix(chatID, "-") || strings.HasPrefix(chatID, "@") {
+		*b = l.group
+	}
+
+	l.buckets[chatID] = b
+
+	return b
+}
+
+// cleanup removes states of chats which are not limited anymore.
+func (l *Limiter) cleanup(now time.Time) {
+	for chatID, b := range l.buckets {
+		if b.tat.Before(now) {
+			delete(l.buckets, chatID)
+		}
+	}
+}
+
+// reserve returns a delay before the next request can be sent.
+func (b *bucket) reserve(now time.Time) time.Duration {
+	tat := b.tat
+	if tat.Before(now) {
+		tat = now
+	}
+
+	b.tat = tat.Add(b.interval)
+
+	if delay := tat.Sub(now) - b.interval*time.Duration(b.burst-1); delay > 0 {
+		return delay
+	}
+
+	return 0
+}
+
+// block forbids any requests until t.
+func (b *bucket) block(t time.Time) {
+	if t = t.Add(b.interval * time.Duration(b.burst-1)); t.After(b.tat) {
+		b.tat = t
+	}
+}
+
+// isLimitedMethod checks that the method sends a new message into the chat.
+func isLimitedMethod(method string) bool {
+	return (strings.HasPrefix(method, "send") && method != MethodSendChatAction) || method == MethodForwardMessage ||
+		method == MethodCopyMessage
+}
+
+// sleep blocks for d duration or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
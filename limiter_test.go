@@ -0,0 +1,93 @@
+package telegram
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	json "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	http "github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func TestLimiterReserve(t *testing.T) {
+	now := time.Now()
+
+	t.Run("private", func(t *testing.T) {
+		l := NewLimiter()
+		assert.Zero(t, l.reserve(now, "42"))
+		assert.Equal(t, time.Second, l.reserve(now, "42"))
+		assert.Zero(t, l.reserve(now, "24"))
+	})
+	t.Run("group", func(t *testing.T) {
+		l := NewLimiter()
+
+		for i := 0; i < LimitGroup; i++ {
+			assert.Zero(t, l.reserve(now.Add(time.Duration(i)*time.Second), "-42"))
+		}
+
+		assert.Equal(t, 3*time.Second, NewLimiter().group.interval)
+		assert.Zero(t, l.reserve(now.Add(time.Minute), "-42"))
+	})
+	t.Run("burst", func(t *testing.T) {
+		l := NewLimiterWithLimits(100, 100, LimitGroup)
+
+		for i := 0; i < LimitGroup; i++ {
+			assert.Zero(t, l.reserve(now, "-42"))
+		}
+
+		assert.Equal(t, 3*time.Second, l.reserve(now, "-42"))
+	})
+	t.Run("global", func(t *testing.T) {
+		l := NewLimiterWithLimits(2, 1, 20)
+		assert.Zero(t, l.reserve(now, "1"))
+		assert.Zero(t, l.reserve(now, "2"))
+		assert.Equal(t, time.Second/2, l.reserve(now, "3"))
+	})
+	t.Run("block", func(t *testing.T) {
+		l := NewLimiter()
+		l.Block("42", time.Hour)
+		assert.True(t, l.reserve(time.Now(), "42") > time.Hour-time.Minute)
+	})
+}
+
+func TestIsLimitedMethod(t *testing.T) {
+	assert.True(t, isLimitedMethod(MethodSendMessage))
+	assert.True(t, isLimitedMethod(MethodCopyMessage))
+	assert.False(t, isLimitedMethod(MethodSendChatAction))
+	assert.False(t, isLimitedMethod(MethodGetUpdates))
+}
+
+func TestBotLimit(t *testing.T) {
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+
+	var calls int
+
+	go http.Serve(ln, func(ctx *http.RequestCtx) {
+		if calls++; calls == 1 {
+			ctx.SetStatusCode(http.StatusTooManyRequests)
+			ctx.SetBodyString(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 1",` +
+				`"parameters":{"retry_after":1}}`)
+
+			return
+		}
+
+		ctx.SetBodyString(`{"ok":true,"result":{"message_id":42}}`)
+	})
+
+	b := Bot{AccessToken: "123:abc", marshler: json.ConfigFastest, transport: NewFastHTTPTransport(&http.Client{
+		Dial: func(string) (net.Conn, error) { return ln.Dial() },
+	})}
+	b.SetEndpoint(Endpoint{URL: "http://telegram.test"})
+	b.SetLimiter(NewLimiter())
+
+	start := time.Now()
+	result, err := b.SendMessageContext(context.Background(), NewMessage(ChatID{ID: 42}, "hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), result.ID)
+	assert.Equal(t, 2, calls)
+	assert.True(t, time.Since(start) >= time.Second)
+}
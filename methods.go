@@ -1,6 +1,7 @@
 package telegram
 
 import (
+	"context"
 	"strconv"
 	"strings"
 )
@@ -909,7 +910,12 @@ type (
 
 // GetMe testing your bot's auth token. Returns basic information about the bot in form of a User object.
 func (b Bot) GetMe() (*User, error) {
-	src, err := b.Do(MethodGetMe, nil)
+	return b.GetMeContext(context.Background())
+}
+
+// GetMeContext is like GetMe but uses ctx for cancellation and deadlines.
+func (b Bot) GetMeContext(ctx context.Context) (*User, error) {
+	src, err := b.DoContext(ctx, MethodGetMe, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -927,7 +933,12 @@ func (b Bot) GetMe() (*User, error) {
 // call, you will not be able to log in again using the same token for 10 minutes. Returns True on success. Requires
 // no parameters.
 func (b Bot) LogOut() (ok bool, err error) {
-	src, err := b.Do(MethodLogOut, nil)
+	return b.LogOutContext(context.Background())
+}
+
+// LogOutContext is like LogOut but uses ctx for cancellation and deadlines.
+func (b Bot) LogOutContext(ctx context.Context) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodLogOut, nil)
 	if err != nil {
 		return false, err
 	}
@@ -949,7 +960,12 @@ func (b Bot) LogOut() (ok bool, err error) {
 // will return error 429 in the first 10 minutes after the bot is launched. Returns True on success. Requires no
 // parameters.
 func (b Bot) Close() (ok bool, err error) {
-	src, err := b.Do(MethodClose, nil)
+	return b.CloseContext(context.Background())
+}
+
+// CloseContext is like Close but uses ctx for cancellation and deadlines.
+func (b Bot) CloseContext(ctx context.Context) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodClose, nil)
 	if err != nil {
 		return false, err
 	}
@@ -975,7 +991,12 @@ func NewMessage(chatID ChatID, text string) SendMessage {
 
 // SendMessage send text messages. On success, the sent Message is returned.
 func (b Bot) SendMessage(p SendMessage) (*Message, error) {
-	src, err := b.Do(MethodSendMessage, p)
+	return b.SendMessageContext(context.Background(), p)
+}
+
+// SendMessageContext is like SendMessage but uses ctx for cancellation and deadlines.
+func (b Bot) SendMessageContext(ctx context.Context, p SendMessage) (*Message, error) {
+	src, err := b.DoContext(ctx, MethodSendMessage, p)
 	if err != nil {
 		return nil, err
 	}
@@ -998,7 +1019,12 @@ func NewForward(fromChatID, toChatID ChatID, messageID int64) ForwardMessage {
 
 // ForwardMessage forward messages of any kind. On success, the sent Message is returned.
 func (b Bot) ForwardMessage(p ForwardMessage) (*Message, error) {
-	src, err := b.Do(MethodForwardMessage, p)
+	return b.ForwardMessageContext(context.Background(), p)
+}
+
+// ForwardMessageContext is like ForwardMessage but uses ctx for cancellation and deadlines.
+func (b Bot) ForwardMessageContext(ctx context.Context, p ForwardMessage) (*Message, error) {
+	src, err := b.DoContext(ctx, MethodForwardMessage, p)
 	if err != nil {
 		return nil, err
 	}
@@ -1014,7 +1040,12 @@ func (b Bot) ForwardMessage(p ForwardMessage) (*Message, error) {
 // CopyMessage copy messages of any kind. The method is analogous to the method forwardMessages, but the copied
 // message doesn't have a link to the original message. Returns the MessageId of the sent message on success.
 func (b Bot) CopyMessage(p CopyMessage) (*MessageID, error) {
-	src, err := b.Do(MethodCopyMessage, p)
+	return b.CopyMessageContext(context.Background(), p)
+}
+
+// CopyMessageContext is like CopyMessage but uses ctx for cancellation and deadlines.
+func (b Bot) CopyMessageContext(ctx context.Context, p CopyMessage) (*MessageID, error) {
+	src, err := b.DoContext(ctx, MethodCopyMessage, p)
 	if err != nil {
 		return nil, err
 	}
@@ -1036,6 +1067,11 @@ func NewPhoto(chatID ChatID, photo *InputFile) SendPhoto {
 
 // SendPhoto send photos. On success, the sent Message is returned.
 func (b Bot) SendPhoto(p SendPhoto) (*Message, error) {
+	return b.SendPhotoContext(context.Background(), p)
+}
+
+// SendPhotoContext is like SendPhoto but uses ctx for cancellation and deadlines.
+func (b Bot) SendPhotoContext(ctx context.Context, p SendPhoto) (*Message, error) {
 	params := make(map[string]string)
 	params["chat_id"] = p.ChatID.String()
 	params["caption"] = p.Caption
@@ -1044,13 +1080,17 @@ func (b Bot) SendPhoto(p SendPhoto) (*Message, error) {
 	params["disable_notification"] = strconv.FormatBool(p.DisableNotification)
 	params["reply_to_message_id"] = strconv.FormatInt(p.ReplyToMessageID, 10)
 
+	params["photo"] = p.Photo.formValue()
+
 	var err error
-	if params["photo"], err = b.marshler.MarshalToString(p.Photo); err != nil {
+	if params["reply_markup"], err = b.marshler.MarshalToString(p.ReplyMarkup); err != nil {
 		return nil, err
 	}
 
-	if params["reply_markup"], err = b.marshler.MarshalToString(p.ReplyMarkup); err != nil {
-		return nil, err
+	if len(p.CaptionEntities) > 0 {
+		if params["caption_entities"], err = b.marshler.MarshalToString(p.CaptionEntities); err != nil {
+			return nil, err
+		}
 	}
 
 	files := make([]*InputFile, 0)
@@ -1058,7 +1098,7 @@ func (b Bot) SendPhoto(p SendPhoto) (*Message, error) {
 		files = append(files, p.Photo)
 	}
 
-	src, err := b.Upload(MethodSendPhoto, params, files...)
+	src, err := b.UploadContext(ctx, MethodSendPhoto, params, files...)
 	if err != nil {
 		return nil, err
 	}
@@ -1082,6 +1122,11 @@ func NewAudio(chatID ChatID, audio *InputFile) SendAudio {
 //
 // For sending voice messages, use the sendVoice method instead.
 func (b Bot) SendAudio(p SendAudio) (*Message, error) {
+	return b.SendAudioContext(context.Background(), p)
+}
+
+// SendAudioContext is like SendAudio but uses ctx for cancellation and deadlines.
+func (b Bot) SendAudioContext(ctx context.Context, p SendAudio) (*Message, error) {
 	params := make(map[string]string)
 	params["chat_id"] = p.ChatID.String()
 	params["caption"] = p.Caption
@@ -1092,17 +1137,18 @@ func (b Bot) SendAudio(p SendAudio) (*Message, error) {
 	params["disable_notification"] = strconv.FormatBool(p.DisableNotification)
 	params["reply_to_message_id"] = strconv.FormatInt(p.ReplyToMessageID, 10)
 
-	var err error
-	if params["audio"], err = b.marshler.MarshalToString(p.Audio); err != nil {
-		return nil, err
-	}
+	params["audio"] = p.Audio.formValue()
+	params["thumb"] = p.Thumb.formValue()
 
-	if params["thumb"], err = b.marshler.MarshalToString(p.Thumb); err != nil {
+	var err error
+	if params["reply_markup"], err = b.marshler.MarshalToString(p.ReplyMarkup); err != nil {
 		return nil, err
 	}
 
-	if params["reply_markup"], err = b.marshler.MarshalToString(p.ReplyMarkup); err != nil {
-		return nil, err
+	if len(p.CaptionEntities) > 0 {
+		if params["caption_entities"], err = b.marshler.MarshalToString(p.CaptionEntities); err != nil {
+			return nil, err
+		}
 	}
 
 	files := make([]*InputFile, 0)
@@ -1114,7 +1160,7 @@ func (b Bot) SendAudio(p SendAudio) (*Message, error) {
 		files = append(files, p.Thumb)
 	}
 
-	src, err := b.Upload(MethodSendAudio, params, files...)
+	src, err := b.UploadContext(ctx, MethodSendAudio, params, files...)
 	if err != nil {
 		return nil, err
 	}
@@ -1136,6 +1182,11 @@ func NewDocument(chatID ChatID, document *InputFile) SendDocument {
 
 // SendDocument send general files. On success, the sent Message is returned. Bots can currently send files of any type of up to 50 MB in size, this limit may be changed in the future.
 func (b Bot) SendDocument(p SendDocument) (*Message, error) {
+	return b.SendDocumentContext(context.Background(), p)
+}
+
+// SendDocumentContext is like SendDocument but uses ctx for cancellation and deadlines.
+func (b Bot) SendDocumentContext(ctx context.Context, p SendDocument) (*Message, error) {
 	params := make(map[string]string)
 	params["chat_id"] = p.ChatID.String()
 	params["caption"] = p.Caption
@@ -1143,13 +1194,17 @@ func (b Bot) SendDocument(p SendDocument) (*Message, error) {
 	params["disable_notification"] = strconv.FormatBool(p.DisableNotification)
 	params["reply_to_message_id"] = strconv.FormatInt(p.ReplyToMessageID, 10)
 
+	params["document"] = p.Document.formValue()
+
 	var err error
-	if params["document"], err = b.marshler.MarshalToString(p.Document); err != nil {
+	if params["reply_markup"], err = b.marshler.MarshalToString(p.ReplyMarkup); err != nil {
 		return nil, err
 	}
 
-	if params["reply_markup"], err = b.marshler.MarshalToString(p.ReplyMarkup); err != nil {
-		return nil, err
+	if len(p.CaptionEntities) > 0 {
+		if params["caption_entities"], err = b.marshler.MarshalToString(p.CaptionEntities); err != nil {
+			return nil, err
+		}
 	}
 
 	files := make([]*InputFile, 0)
@@ -1157,7 +1212,7 @@ func (b Bot) SendDocument(p SendDocument) (*Message, error) {
 		files = append(files, p.Document)
 	}
 
-	src, err := b.Upload(MethodSendDocument, params, files...)
+	src, err := b.UploadContext(ctx, MethodSendDocument, params, files...)
 	if err != nil {
 		return nil, err
 	}
@@ -1179,6 +1234,11 @@ func NewVideo(chatID ChatID, video *InputFile) SendVideo {
 
 // SendVideo send video files, Telegram clients support mp4 videos (other formats may be sent as Document). On success, the sent Message is returned. Bots can currently send video files of up to 50 MB in size, this limit may be changed in the future.
 func (b Bot) SendVideo(p SendVideo) (*Message, error) {
+	return b.SendVideoContext(context.Background(), p)
+}
+
+// SendVideoContext is like SendVideo but uses ctx for cancellation and deadlines.
+func (b Bot) SendVideoContext(ctx context.Context, p SendVideo) (*Message, error) {
 	params := make(map[string]string)
 	params["chat_id"] = p.ChatID.String()
 	params["duration"] = strconv.Itoa(p.Duration)
@@ -1190,17 +1250,18 @@ func (b Bot) SendVideo(p SendVideo) (*Message, error) {
 	params["disable_notification"] = strconv.FormatBool(p.DisableNotification)
 	params["reply_to_message_id"] = strconv.FormatInt(p.ReplyToMessageID, 10)
 
-	var err error
-	if params["video"], err = b.marshler.MarshalToString(p.Video); err != nil {
-		return nil, err
-	}
+	params["video"] = p.Video.formValue()
+	params["thumb"] = p.Thumb.formValue()
 
-	if params["thumb"], err = b.marshler.MarshalToString(p.Thumb); err != nil {
+	var err error
+	if params["reply_markup"], err = b.marshler.MarshalToString(p.ReplyMarkup); err != nil {
 		return nil, err
 	}
 
-	if params["reply_markup"], err = b.marshler.MarshalToString(p.ReplyMarkup); err != nil {
-		return nil, err
+	if len(p.CaptionEntities) > 0 {
+		if params["caption_entities"], err = b.marshler.MarshalToString(p.CaptionEntities); err != nil {
+			return nil, err
+		}
 	}
 
 	files := make([]*InputFile, 0)
@@ -1212,7 +1273,7 @@ func (b Bot) SendVideo(p SendVideo) (*Message, error) {
 		files = append(files, p.Thumb)
 	}
 
-	src, err := b.Upload(MethodSendVideo, params, files...)
+	src, err := b.UploadContext(ctx, MethodSendVideo, params, files...)
 	if err != nil {
 		return nil, err
 	}
@@ -1234,6 +1295,11 @@ func NewAnimation(chatID ChatID, animation *InputFile) SendAnimation {
 
 // SendAnimation send animation files (GIF or H.264/MPEG-4 AVC video without sound). On success, the sent Message is returned. Bots can currently send animation files of up to 50 MB in size, this limit may be changed in the future.
 func (b Bot) SendAnimation(p SendAnimation) (*Message, error) {
+	return b.SendAnimationContext(context.Background(), p)
+}
+
+// SendAnimationContext is like SendAnimation but uses ctx for cancellation and deadlines.
+func (b Bot) SendAnimationContext(ctx context.Context, p SendAnimation) (*Message, error) {
 	params := make(map[string]string)
 	params["chat_id"] = p.ChatID.String()
 	params["duration"] = strconv.Itoa(p.Duration)
@@ -1244,17 +1310,18 @@ func (b Bot) SendAnimation(p SendAnimation) (*Message, error) {
 	params["disable_notification"] = strconv.FormatBool(p.DisableNotification)
 	params["reply_to_message_id"] = strconv.FormatInt(p.ReplyToMessageID, 10)
 
-	var err error
-	if params["animation"], err = b.marshler.MarshalToString(p.Animation); err != nil {
-		return nil, err
-	}
+	params["animation"] = p.Animation.formValue()
+	params["thumb"] = p.Thumb.formValue()
 
-	if params["thumb"], err = b.marshler.MarshalToString(p.Thumb); err != nil {
+	var err error
+	if params["reply_markup"], err = b.marshler.MarshalToString(p.ReplyMarkup); err != nil {
 		return nil, err
 	}
 
-	if params["reply_markup"], err = b.marshler.MarshalToString(p.ReplyMarkup); err != nil {
-		return nil, err
+	if len(p.CaptionEntities) > 0 {
+		if params["caption_entities"], err = b.marshler.MarshalToString(p.CaptionEntities); err != nil {
+			return nil, err
+		}
 	}
 
 	files := make([]*InputFile, 0)
@@ -1266,7 +1333,7 @@ func (b Bot) SendAnimation(p SendAnimation) (*Message, error) {
 		files = append(files, p.Thumb)
 	}
 
-	src, err := b.Upload(MethodSendAnimation, params, files...)
+	src, err := b.UploadContext(ctx, MethodSendAnimation, params, files...)
 	if err != nil {
 		return nil, err
 	}
@@ -1288,6 +1355,11 @@ func NewVoice(chatID ChatID, voice *InputFile) SendVoice {
 
 // SendVoice send audio files, if you want Telegram clients to display the file as a playable voice message. For this to work, your audio must be in an .ogg file encoded with OPUS (other formats may be sent as Audio or Document). On success, the sent Message is returned. Bots can currently send voice messages of up to 50 MB in size, this limit may be changed in the future.
 func (b Bot) SendVoice(p SendVoice) (*Message, error) {
+	return b.SendVoiceContext(context.Background(), p)
+}
+
+// SendVoiceContext is like SendVoice but uses ctx for cancellation and deadlines.
+func (b Bot) SendVoiceContext(ctx context.Context, p SendVoice) (*Message, error) {
 	params := make(map[string]string)
 	params["chat_id"] = p.ChatID.String()
 	params["duration"] = strconv.Itoa(p.Duration)
@@ -1296,13 +1368,17 @@ func (b Bot) SendVoice(p SendVoice) (*Message, error) {
 	params["disable_notification"] = strconv.FormatBool(p.DisableNotification)
 	params["reply_to_message_id"] = strconv.FormatInt(p.ReplyToMessageID, 10)
 
+	params["voice"] = p.Voice.formValue()
+
 	var err error
-	if params["voice"], err = b.marshler.MarshalToString(p.Voice); err != nil {
+	if params["reply_markup"], err = b.marshler.MarshalToString(p.ReplyMarkup); err != nil {
 		return nil, err
 	}
 
-	if params["reply_markup"], err = b.marshler.MarshalToString(p.ReplyMarkup); err != nil {
-		return nil, err
+	if len(p.CaptionEntities) > 0 {
+		if params["caption_entities"], err = b.marshler.MarshalToString(p.CaptionEntities); err != nil {
+			return nil, err
+		}
 	}
 
 	files := make([]*InputFile, 0)
@@ -1310,7 +1386,7 @@ func (b Bot) SendVoice(p SendVoice) (*Message, error) {
 		files = append(files, p.Voice)
 	}
 
-	src, err := b.Upload(MethodSendVoice, params, files...)
+	src, err := b.UploadContext(ctx, MethodSendVoice, params, files...)
 	if err != nil {
 		return nil, err
 	}
@@ -1332,6 +1408,11 @@ func NewVideoNote(chatID ChatID, videoNote *InputFile) SendVideoNote {
 
 // SendVideoNote send video messages. On success, the sent Message is returned.
 func (b Bot) SendVideoNote(p SendVideoNote) (*Message, error) {
+	return b.SendVideoNoteContext(context.Background(), p)
+}
+
+// SendVideoNoteContext is like SendVideoNote but uses ctx for cancellation and deadlines.
+func (b Bot) SendVideoNoteContext(ctx context.Context, p SendVideoNote) (*Message, error) {
 	params := make(map[string]string)
 	params["chat_id"] = p.ChatID.String()
 	params["duration"] = strconv.Itoa(p.Duration)
@@ -1339,15 +1420,10 @@ func (b Bot) SendVideoNote(p SendVideoNote) (*Message, error) {
 	params["disable_notification"] = strconv.FormatBool(p.DisableNotification)
 	params["reply_to_message_id"] = strconv.FormatInt(p.ReplyToMessageID, 10)
 
-	var err error
-	if params["video_note"], err = b.marshler.MarshalToString(p.VideoNote); err != nil {
-		return nil, err
-	}
-
-	if params["thumb"], err = b.marshler.MarshalToString(p.Thumb); err != nil {
-		return nil, err
-	}
+	params["video_note"] = p.VideoNote.formValue()
+	params["thumb"] = p.Thumb.formValue()
 
+	var err error
 	if params["reply_markup"], err = b.marshler.MarshalToString(p.ReplyMarkup); err != nil {
 		return nil, err
 	}
@@ -1361,7 +1437,7 @@ func (b Bot) SendVideoNote(p SendVideoNote) (*Message, error) {
 		files = append(files, p.Thumb)
 	}
 
-	src, err := b.Upload(MethodSendVideoNote, params, files...)
+	src, err := b.UploadContext(ctx, MethodSendVideoNote, params, files...)
 	if err != nil {
 		return nil, err
 	}
@@ -1383,6 +1459,11 @@ func NewMediaGroup(chatID ChatID, media ...AlbumMedia) SendMediaGroup {
 
 // SendMediaGroup send a group of photos or videos as an album. On success, an array of the sent Messages is returned.
 func (b Bot) SendMediaGroup(p SendMediaGroup) ([]*Message, error) {
+	return b.SendMediaGroupContext(context.Background(), p)
+}
+
+// SendMediaGroupContext is like SendMediaGroup but uses ctx for cancellation and deadlines.
+func (b Bot) SendMediaGroupContext(ctx context.Context, p SendMediaGroup) ([]*Message, error) {
 	media := make([]string, len(p.Media), 10)
 	files := make([]*InputFile, 0)
 
@@ -1407,7 +1488,7 @@ func (b Bot) SendMediaGroup(p SendMediaGroup) ([]*Message, error) {
 	params["reply_to_message_id"] = strconv.FormatInt(p.ReplyToMessageID, 10)
 	params["media"] = "[" + strings.Join(media, ",") + "]"
 
-	src, err := b.Upload(MethodSendMediaGroup, params, files...)
+	src, err := b.UploadContext(ctx, MethodSendMediaGroup, params, files...)
 	if err != nil {
 		return nil, err
 	}
@@ -1430,7 +1511,12 @@ func NewLocation(chatID ChatID, latitude, longitude float64) SendLocation {
 
 // SendLocation send point on the map. On success, the sent Message is returned.
 func (b Bot) SendLocation(p SendLocation) (*Message, error) {
-	src, err := b.Do(MethodSendLocation, p)
+	return b.SendLocationContext(context.Background(), p)
+}
+
+// SendLocationContext is like SendLocation but uses ctx for cancellation and deadlines.
+func (b Bot) SendLocationContext(ctx context.Context, p SendLocation) (*Message, error) {
+	src, err := b.DoContext(ctx, MethodSendLocation, p)
 	if err != nil {
 		return nil, err
 	}
@@ -1452,7 +1538,12 @@ func NewLiveLocation(latitude, longitude float64) EditMessageLiveLocation {
 
 // EditMessageLiveLocation edit live location messages. A location can be edited until its live_period expires or editing is explicitly disabled by a call to stopMessageLiveLocation. On success, if the edited message was sent by the bot, the edited Message is returned, otherwise True is returned.
 func (b Bot) EditMessageLiveLocation(p EditMessageLiveLocation) (*Message, error) {
-	src, err := b.Do(MethodEditMessageLiveLocation, p)
+	return b.EditMessageLiveLocationContext(context.Background(), p)
+}
+
+// EditMessageLiveLocationContext is like EditMessageLiveLocation but uses ctx for cancellation and deadlines.
+func (b Bot) EditMessageLiveLocationContext(ctx context.Context, p EditMessageLiveLocation) (*Message, error) {
+	src, err := b.DoContext(ctx, MethodEditMessageLiveLocation, p)
 	if err != nil {
 		return nil, err
 	}
@@ -1467,7 +1558,12 @@ func (b Bot) EditMessageLiveLocation(p EditMessageLiveLocation) (*Message, error
 
 // StopMessageLiveLocation stop updating a live location message before live_period expires. On success, if the message was sent by the bot, the sent Message is returned, otherwise True is returned.
 func (b Bot) StopMessageLiveLocation(p StopMessageLiveLocation) (*Message, error) {
-	src, err := b.Do(MethodStopMessageLiveLocation, p)
+	return b.StopMessageLiveLocationContext(context.Background(), p)
+}
+
+// StopMessageLiveLocationContext is like StopMessageLiveLocation but uses ctx for cancellation and deadlines.
+func (b Bot) StopMessageLiveLocationContext(ctx context.Context, p StopMessageLiveLocation) (*Message, error) {
+	src, err := b.DoContext(ctx, MethodStopMessageLiveLocation, p)
 	if err != nil {
 		return nil, err
 	}
@@ -1492,7 +1588,12 @@ func NewVenue(chatID ChatID, lat, long float64, title, address string) SendVenue
 
 // SendVenue send information about a venue. On success, the sent Message is returned.
 func (b Bot) SendVenue(p SendVenue) (*Message, error) {
-	src, err := b.Do(MethodSendVenue, p)
+	return b.SendVenueContext(context.Background(), p)
+}
+
+// SendVenueContext is like SendVenue but uses ctx for cancellation and deadlines.
+func (b Bot) SendVenueContext(ctx context.Context, p SendVenue) (*Message, error) {
+	src, err := b.DoContext(ctx, MethodSendVenue, p)
 	if err != nil {
 		return nil, err
 	}
@@ -1515,7 +1616,12 @@ func NewContact(chatID ChatID, phoneNumber, firstName string) SendContact {
 
 // SendContact send phone contacts. On success, the sent Message is returned.
 func (b Bot) SendContact(p SendContact) (*Message, error) {
-	src, err := b.Do(MethodSendContact, p)
+	return b.SendContactContext(context.Background(), p)
+}
+
+// SendContactContext is like SendContact but uses ctx for cancellation and deadlines.
+func (b Bot) SendContactContext(ctx context.Context, p SendContact) (*Message, error) {
+	src, err := b.DoContext(ctx, MethodSendContact, p)
 	if err != nil {
 		return nil, err
 	}
@@ -1538,7 +1644,12 @@ func NewPoll(chatID ChatID, question string, options ...string) SendPoll {
 
 // SendPoll send a native poll. A native poll can't be sent to a private chat. On success, the sent Message is returned.
 func (b Bot) SendPoll(p SendPoll) (*Message, error) {
-	src, err := b.Do(MethodSendPoll, p)
+	return b.SendPollContext(context.Background(), p)
+}
+
+// SendPollContext is like SendPoll but uses ctx for cancellation and deadlines.
+func (b Bot) SendPollContext(ctx context.Context, p SendPoll) (*Message, error) {
+	src, err := b.DoContext(ctx, MethodSendPoll, p)
 	if err != nil {
 		return nil, err
 	}
@@ -1555,7 +1666,12 @@ func (b Bot) SendPoll(p SendPoll) (*Message, error) {
 // we're aware of the “proper” singular of die. But it's awkward, and we decided to help it change. One dice at a
 // time!)
 func (b Bot) SendDice(p SendDice) (*Message, error) {
-	src, err := b.Do(MethodSendDice, p)
+	return b.SendDiceContext(context.Background(), p)
+}
+
+// SendDiceContext is like SendDice but uses ctx for cancellation and deadlines.
+func (b Bot) SendDiceContext(ctx context.Context, p SendDice) (*Message, error) {
+	src, err := b.DoContext(ctx, MethodSendDice, p)
 	if err != nil {
 		return nil, err
 	}
@@ -1572,7 +1688,12 @@ func (b Bot) SendDice(p SendDice) (*Message, error) {
 //
 // We only recommend using this method when a response from the bot will take a noticeable amount of time to arrive.
 func (b Bot) SendChatAction(p SendChatAction) (ok bool, err error) {
-	src, err := b.Do(MethodSendChatAction, p)
+	return b.SendChatActionContext(context.Background(), p)
+}
+
+// SendChatActionContext is like SendChatAction but uses ctx for cancellation and deadlines.
+func (b Bot) SendChatActionContext(ctx context.Context, p SendChatAction) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodSendChatAction, p)
 	if err != nil {
 		return ok, err
 	}
@@ -1591,7 +1712,12 @@ func (b Bot) SendChatAction(p SendChatAction) (ok bool, err error) {
 
 // GetUserProfilePhotos get a list of profile pictures for a user. Returns a UserProfilePhotos object.
 func (b Bot) GetUserProfilePhotos(p GetUserProfilePhotos) (*UserProfilePhotos, error) {
-	src, err := b.Do(MethodGetUserProfilePhotos, p)
+	return b.GetUserProfilePhotosContext(context.Background(), p)
+}
+
+// GetUserProfilePhotosContext is like GetUserProfilePhotos but uses ctx for cancellation and deadlines.
+func (b Bot) GetUserProfilePhotosContext(ctx context.Context, p GetUserProfilePhotos) (*UserProfilePhotos, error) {
+	src, err := b.DoContext(ctx, MethodGetUserProfilePhotos, p)
 	if err != nil {
 		return nil, err
 	}
@@ -1608,7 +1734,12 @@ func (b Bot) GetUserProfilePhotos(p GetUserProfilePhotos) (*UserProfilePhotos, e
 //
 // Note: This function may not preserve the original file name and MIME type. You should save the file's MIME type and name (if available) when the File object is received.
 func (b Bot) GetFile(fid string) (*File, error) {
-	src, err := b.Do(MethodGetFile, GetFile{FileID: fid})
+	return b.GetFileContext(context.Background(), fid)
+}
+
+// GetFileContext is like GetFile but uses ctx for cancellation and deadlines.
+func (b Bot) GetFileContext(ctx context.Context, fid string) (*File, error) {
+	src, err := b.DoContext(ctx, MethodGetFile, GetFile{FileID: fid})
 	if err != nil {
 		return nil, err
 	}
@@ -1632,7 +1763,12 @@ func NewKick(chatID ChatID, userID int64) BanChatMember {
 //
 // Note: In regular groups (non-supergroups), this method will only work if the 'All Members Are Admins' setting is off in the target group. Otherwise members may only be removed by the group's creator or by the member that added them.
 func (b Bot) BanChatMember(p BanChatMember) (ok bool, err error) {
-	src, err := b.Do(MethodBanChatMember, p)
+	return b.BanChatMemberContext(context.Background(), p)
+}
+
+// BanChatMemberContext is like BanChatMember but uses ctx for cancellation and deadlines.
+func (b Bot) BanChatMemberContext(ctx context.Context, p BanChatMember) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodBanChatMember, p)
 	if err != nil {
 		return ok, err
 	}
@@ -1651,7 +1787,12 @@ func (b Bot) BanChatMember(p BanChatMember) (ok bool, err error) {
 
 // UnbanChatMember unban a previously kicked user in a supergroup or channel. The user will not return to the group or channel automatically, but will be able to join via link, etc. The bot must be an administrator for this to work. Returns True on success.
 func (b Bot) UnbanChatMember(p UnbanChatMember) (ok bool, err error) {
-	src, err := b.Do(MethodUnbanChatMember, p)
+	return b.UnbanChatMemberContext(context.Background(), p)
+}
+
+// UnbanChatMemberContext is like UnbanChatMember but uses ctx for cancellation and deadlines.
+func (b Bot) UnbanChatMemberContext(ctx context.Context, p UnbanChatMember) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodUnbanChatMember, p)
 	if err != nil {
 		return ok, err
 	}
@@ -1678,7 +1819,12 @@ func NewRestrict(chatID ChatID, userID int64, permissions ChatPermissions) Restr
 
 // restrict a user in a supergroup. The bot must be an administrator in the supergroup for this to work and must have the appropriate admin rights. Pass True for all permissions to lift restrictions from a user. Returns True on success.
 func (b Bot) RestrictChatMember(p RestrictChatMember) (ok bool, err error) {
-	src, err := b.Do(MethodRestrictChatMember, p)
+	return b.RestrictChatMemberContext(context.Background(), p)
+}
+
+// RestrictChatMemberContext is like RestrictChatMember but uses ctx for cancellation and deadlines.
+func (b Bot) RestrictChatMemberContext(ctx context.Context, p RestrictChatMember) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodRestrictChatMember, p)
 	if err != nil {
 		return ok, err
 	}
@@ -1704,7 +1850,12 @@ func NewPromote(chatID ChatID, userID int64) PromoteChatMember {
 
 // PromoteChatMember promote or demote a user in a supergroup or a channel. The bot must be an administrator in the chat for this to work and must have the appropriate admin rights. Pass False for all boolean  to demote a user. Returns True on success.
 func (b Bot) PromoteChatMember(p PromoteChatMember) (ok bool, err error) {
-	src, err := b.Do(MethodPromoteChatMember, p)
+	return b.PromoteChatMemberContext(context.Background(), p)
+}
+
+// PromoteChatMemberContext is like PromoteChatMember but uses ctx for cancellation and deadlines.
+func (b Bot) PromoteChatMemberContext(ctx context.Context, p PromoteChatMember) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodPromoteChatMember, p)
 	if err != nil {
 		return ok, err
 	}
@@ -1723,7 +1874,12 @@ func (b Bot) PromoteChatMember(p PromoteChatMember) (ok bool, err error) {
 
 // SetChatAdministratorCustomTitle method to set a custom title for an administrator in a supergroup promoted by the b. Returns True on success.
 func (b Bot) SetChatAdministratorCustomTitle(p SetChatAdministratorCustomTitle) (ok bool, err error) {
-	src, err := b.Do(MethodSetChatAdministratorCustomTitle, p)
+	return b.SetChatAdministratorCustomTitleContext(context.Background(), p)
+}
+
+// SetChatAdministratorCustomTitleContext is like SetChatAdministratorCustomTitle but uses ctx for cancellation and deadlines.
+func (b Bot) SetChatAdministratorCustomTitleContext(ctx context.Context, p SetChatAdministratorCustomTitle) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodSetChatAdministratorCustomTitle, p)
 	if err != nil {
 		return ok, err
 	}
@@ -1742,7 +1898,12 @@ func (b Bot) SetChatAdministratorCustomTitle(p SetChatAdministratorCustomTitle)
 
 // SetChatPermissions set default chat permissions for all members. The bot must be an administrator in the group or a supergroup for this to work and must have the can_restrict_members admin rights. Returns True on success.
 func (b Bot) SetChatPermissions(p SetChatPermissions) (ok bool, err error) {
-	src, err := b.Do(MethodSetChatPermissions, p)
+	return b.SetChatPermissionsContext(context.Background(), p)
+}
+
+// SetChatPermissionsContext is like SetChatPermissions but uses ctx for cancellation and deadlines.
+func (b Bot) SetChatPermissionsContext(ctx context.Context, p SetChatPermissions) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodSetChatPermissions, p)
 	if err != nil {
 		return ok, err
 	}
@@ -1761,7 +1922,12 @@ func (b Bot) SetChatPermissions(p SetChatPermissions) (ok bool, err error) {
 
 // ExportChatInviteLink export an invite link to a supergroup or a channel. The bot must be an administrator in the chat for this to work and must have the appropriate admin rights. Returns exported invite link as String on success.
 func (b Bot) ExportChatInviteLink(p ExportChatInviteLink) (string, error) {
-	src, err := b.Do(MethodExportChatInviteLink, p)
+	return b.ExportChatInviteLinkContext(context.Background(), p)
+}
+
+// ExportChatInviteLinkContext is like ExportChatInviteLink but uses ctx for cancellation and deadlines.
+func (b Bot) ExportChatInviteLinkContext(ctx context.Context, p ExportChatInviteLink) (string, error) {
+	src, err := b.DoContext(ctx, MethodExportChatInviteLink, p)
 	if err != nil {
 		return "", err
 	}
@@ -1783,7 +1949,12 @@ func (b Bot) ExportChatInviteLink(p ExportChatInviteLink) (string, error) {
 // this to work and must have the appropriate admin rights. The link can be revoked using the method
 // revokeChatInviteLink. Returns the new invite link as ChatInviteLink object.
 func (b Bot) CreateChatInviteLink(p CreateChatInviteLink) (*ChatInviteLink, error) {
-	src, err := b.Do(MethodCreateChatInviteLink, p)
+	return b.CreateChatInviteLinkContext(context.Background(), p)
+}
+
+// CreateChatInviteLinkContext is like CreateChatInviteLink but uses ctx for cancellation and deadlines.
+func (b Bot) CreateChatInviteLinkContext(ctx context.Context, p CreateChatInviteLink) (*ChatInviteLink, error) {
+	src, err := b.DoContext(ctx, MethodCreateChatInviteLink, p)
 	if err != nil {
 		return nil, err
 	}
@@ -1805,7 +1976,12 @@ func (b Bot) CreateChatInviteLink(p CreateChatInviteLink) (*ChatInviteLink, erro
 // the chat for this to work and must have the appropriate admin rights. Returns the edited invite link as a
 // ChatInviteLink object.
 func (b Bot) EditChatInviteLink(p EditChatInviteLink) (*ChatInviteLink, error) {
-	src, err := b.Do(MethodEditChatInviteLink, p)
+	return b.EditChatInviteLinkContext(context.Background(), p)
+}
+
+// EditChatInviteLinkContext is like EditChatInviteLink but uses ctx for cancellation and deadlines.
+func (b Bot) EditChatInviteLinkContext(ctx context.Context, p EditChatInviteLink) (*ChatInviteLink, error) {
+	src, err := b.DoContext(ctx, MethodEditChatInviteLink, p)
 	if err != nil {
 		return nil, err
 	}
@@ -1827,7 +2003,12 @@ func (b Bot) EditChatInviteLink(p EditChatInviteLink) (*ChatInviteLink, error) {
 // is automatically generated. The bot must be an administrator in the chat for this to work and must have the
 // appropriate admin rights. Returns the revoked invite link as ChatInviteLink object.
 func (b Bot) RevokeChatInviteLink(p RevokeChatInviteLink) (*ChatInviteLink, error) {
-	src, err := b.Do(MethodRevokeChatInviteLink, p)
+	return b.RevokeChatInviteLinkContext(context.Background(), p)
+}
+
+// RevokeChatInviteLinkContext is like RevokeChatInviteLink but uses ctx for cancellation and deadlines.
+func (b Bot) RevokeChatInviteLinkContext(ctx context.Context, p RevokeChatInviteLink) (*ChatInviteLink, error) {
+	src, err := b.DoContext(ctx, MethodRevokeChatInviteLink, p)
 	if err != nil {
 		return nil, err
 	}
@@ -1847,19 +2028,22 @@ func (b Bot) RevokeChatInviteLink(p RevokeChatInviteLink) (*ChatInviteLink, erro
 
 // SetChatPhoto set a new profile photo for the chat. Photos can't be changed for private chats. The bot must be an administrator in the chat for this to work and must have the appropriate admin rights. Returns True on success.
 func (b Bot) SetChatPhoto(cid int64, photo *InputFile) (ok bool, err error) {
+	return b.SetChatPhotoContext(context.Background(), cid, photo)
+}
+
+// SetChatPhotoContext is like SetChatPhoto but uses ctx for cancellation and deadlines.
+func (b Bot) SetChatPhotoContext(ctx context.Context, cid int64, photo *InputFile) (ok bool, err error) {
 	params := make(map[string]string)
 	params["chat_id"] = strconv.FormatInt(cid, 10)
 
-	if params["photo"], err = b.marshler.MarshalToString(photo); err != nil {
-		return
-	}
+	params["photo"] = photo.formValue()
 
 	files := make([]*InputFile, 0)
 	if photo.IsAttachment() {
 		files = append(files, photo)
 	}
 
-	src, err := b.Upload(MethodSetChatPhoto, params, files...)
+	src, err := b.UploadContext(ctx, MethodSetChatPhoto, params, files...)
 	if err != nil {
 		return ok, err
 	}
@@ -1878,7 +2062,12 @@ func (b Bot) SetChatPhoto(cid int64, photo *InputFile) (ok bool, err error) {
 
 // DeleteChatPhoto delete a chat photo. Photos can't be changed for private chats. The bot must be an administrator in the chat for this to work and must have the appropriate admin rights. Returns True on success.
 func (b Bot) DeleteChatPhoto(p DeleteChatPhoto) (ok bool, err error) {
-	src, err := b.Do(MethodDeleteChatPhoto, p)
+	return b.DeleteChatPhotoContext(context.Background(), p)
+}
+
+// DeleteChatPhotoContext is like DeleteChatPhoto but uses ctx for cancellation and deadlines.
+func (b Bot) DeleteChatPhotoContext(ctx context.Context, p DeleteChatPhoto) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodDeleteChatPhoto, p)
 	if err != nil {
 		return ok, err
 	}
@@ -1897,7 +2086,12 @@ func (b Bot) DeleteChatPhoto(p DeleteChatPhoto) (ok bool, err error) {
 
 // SetChatTitle change the title of a chat. Titles can't be changed for private chats. The bot must be an administrator in the chat for this to work and must have the appropriate admin rights. Returns True on success.
 func (b Bot) SetChatTitle(p SetChatTitle) (ok bool, err error) {
-	src, err := b.Do(MethodSetChatTitle, p)
+	return b.SetChatTitleContext(context.Background(), p)
+}
+
+// SetChatTitleContext is like SetChatTitle but uses ctx for cancellation and deadlines.
+func (b Bot) SetChatTitleContext(ctx context.Context, p SetChatTitle) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodSetChatTitle, p)
 	if err != nil {
 		return ok, err
 	}
@@ -1916,7 +2110,12 @@ func (b Bot) SetChatTitle(p SetChatTitle) (ok bool, err error) {
 
 // SetChatDescription change the description of a group, a supergroup or a channel. The bot must be an administrator in the chat for this to work and must have the appropriate admin rights. Returns True on success.
 func (b Bot) SetChatDescription(p SetChatDescription) (ok bool, err error) {
-	src, err := b.Do(MethodSetChatDescription, p)
+	return b.SetChatDescriptionContext(context.Background(), p)
+}
+
+// SetChatDescriptionContext is like SetChatDescription but uses ctx for cancellation and deadlines.
+func (b Bot) SetChatDescriptionContext(ctx context.Context, p SetChatDescription) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodSetChatDescription, p)
 	if err != nil {
 		return ok, err
 	}
@@ -1942,7 +2141,12 @@ func NewPin(chatID ChatID, messageID int64) PinChatMessage {
 
 // PinChatMessage pin a message in a group, a supergroup, or a channel. The bot must be an administrator in the chat for this to work and must have the ‘can_pin_messages’ admin right in the supergroup or ‘can_edit_messages’ admin right in the channel. Returns True on success.
 func (b Bot) PinChatMessage(p PinChatMessage) (ok bool, err error) {
-	src, err := b.Do(MethodPinChatMessage, p)
+	return b.PinChatMessageContext(context.Background(), p)
+}
+
+// PinChatMessageContext is like PinChatMessage but uses ctx for cancellation and deadlines.
+func (b Bot) PinChatMessageContext(ctx context.Context, p PinChatMessage) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodPinChatMessage, p)
 	if err != nil {
 		return ok, err
 	}
@@ -1961,7 +2165,12 @@ func (b Bot) PinChatMessage(p PinChatMessage) (ok bool, err error) {
 
 // UnpinChatMessage unpin a message in a group, a supergroup, or a channel. The bot must be an administrator in the chat for this to work and must have the ‘can_pin_messages’ admin right in the supergroup or ‘can_edit_messages’ admin right in the channel. Returns True on success.
 func (b Bot) UnpinChatMessage(p UnpinChatMessage) (ok bool, err error) {
-	src, err := b.Do(MethodUnpinChatMessage, p)
+	return b.UnpinChatMessageContext(context.Background(), p)
+}
+
+// UnpinChatMessageContext is like UnpinChatMessage but uses ctx for cancellation and deadlines.
+func (b Bot) UnpinChatMessageContext(ctx context.Context, p UnpinChatMessage) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodUnpinChatMessage, p)
 	if err != nil {
 		return ok, err
 	}
@@ -1982,7 +2191,12 @@ func (b Bot) UnpinChatMessage(p UnpinChatMessage) (ok bool, err error) {
 // must be an administrator in the chat for this to work and must have the 'can_pin_messages' admin right in a
 // supergroup or 'can_edit_messages' admin right in a channel. Returns True on success.
 func (b Bot) UnpinAllChatMessages(p UnpinAllChatMessages) (ok bool, err error) {
-	src, err := b.Do(MethodUnpinAllChatMessages, p)
+	return b.UnpinAllChatMessagesContext(context.Background(), p)
+}
+
+// UnpinAllChatMessagesContext is like UnpinAllChatMessages but uses ctx for cancellation and deadlines.
+func (b Bot) UnpinAllChatMessagesContext(ctx context.Context, p UnpinAllChatMessages) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodUnpinAllChatMessages, p)
 	if err != nil {
 		return ok, err
 	}
@@ -2001,7 +2215,12 @@ func (b Bot) UnpinAllChatMessages(p UnpinAllChatMessages) (ok bool, err error) {
 
 // LeaveChat leave a group, supergroup or channel. Returns True on success.
 func (b Bot) LeaveChat(p LeaveChat) (ok bool, err error) {
-	src, err := b.Do(MethodLeaveChat, p)
+	return b.LeaveChatContext(context.Background(), p)
+}
+
+// LeaveChatContext is like LeaveChat but uses ctx for cancellation and deadlines.
+func (b Bot) LeaveChatContext(ctx context.Context, p LeaveChat) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodLeaveChat, p)
 	if err != nil {
 		return ok, err
 	}
@@ -2020,7 +2239,12 @@ func (b Bot) LeaveChat(p LeaveChat) (ok bool, err error) {
 
 // GetChat get up to date information about the chat (current name of the user for one-on-one conversations, current username of a user, group or channel, etc.). Returns a Chat object on success.
 func (b Bot) GetChat(p GetChat) (*Chat, error) {
-	src, err := b.Do(MethodGetChat, p)
+	return b.GetChatContext(context.Background(), p)
+}
+
+// GetChatContext is like GetChat but uses ctx for cancellation and deadlines.
+func (b Bot) GetChatContext(ctx context.Context, p GetChat) (*Chat, error) {
+	src, err := b.DoContext(ctx, MethodGetChat, p)
 	if err != nil {
 		return nil, err
 	}
@@ -2035,7 +2259,12 @@ func (b Bot) GetChat(p GetChat) (*Chat, error) {
 
 // GetChatAdministrators get a list of administrators in a chat. On success, returns an Array of ChatMember objects that contains information about all chat administrators except other bots. If the chat is a group or a supergroup and no administrators were appointed, only the creator will be returned.
 func (b Bot) GetChatAdministrators(p GetChatAdministrators) ([]*ChatMember, error) {
-	src, err := b.Do(MethodGetChatAdministrators, p)
+	return b.GetChatAdministratorsContext(context.Background(), p)
+}
+
+// GetChatAdministratorsContext is like GetChatAdministrators but uses ctx for cancellation and deadlines.
+func (b Bot) GetChatAdministratorsContext(ctx context.Context, p GetChatAdministrators) ([]*ChatMember, error) {
+	src, err := b.DoContext(ctx, MethodGetChatAdministrators, p)
 	if err != nil {
 		return nil, err
 	}
@@ -2050,7 +2279,12 @@ func (b Bot) GetChatAdministrators(p GetChatAdministrators) ([]*ChatMember, erro
 
 // GetChatMemberCount get the number of members in a chat. Returns Int on success.
 func (b Bot) GetChatMemberCount(p GetChatMemberCount) (int, error) {
-	src, err := b.Do(MethodGetChatMemberCount, p)
+	return b.GetChatMemberCountContext(context.Background(), p)
+}
+
+// GetChatMemberCountContext is like GetChatMemberCount but uses ctx for cancellation and deadlines.
+func (b Bot) GetChatMemberCountContext(ctx context.Context, p GetChatMemberCount) (int, error) {
+	src, err := b.DoContext(ctx, MethodGetChatMemberCount, p)
 	if err != nil {
 		return 0, err
 	}
@@ -2070,7 +2304,12 @@ func (b Bot) GetChatMemberCount(p GetChatMemberCount) (int, error) {
 
 // GetChatMember get information about a member of a chat. Returns a ChatMember object on success.
 func (b Bot) GetChatMember(p GetChatMember) (*ChatMember, error) {
-	src, err := b.Do(MethodGetChatMember, p)
+	return b.GetChatMemberContext(context.Background(), p)
+}
+
+// GetChatMemberContext is like GetChatMember but uses ctx for cancellation and deadlines.
+func (b Bot) GetChatMemberContext(ctx context.Context, p GetChatMember) (*ChatMember, error) {
+	src, err := b.DoContext(ctx, MethodGetChatMember, p)
 	if err != nil {
 		return nil, err
 	}
@@ -2085,7 +2324,12 @@ func (b Bot) GetChatMember(p GetChatMember) (*ChatMember, error) {
 
 // SetChatStickerSet set a new group sticker set for a supergroup. The bot must be an administrator in the chat for this to work and must have the appropriate admin rights. Use the field can_set_sticker_set optionally returned in getChat requests to check if the bot can use this method. Returns True on success.
 func (b Bot) SetChatStickerSet(p SetChatStickerSet) (ok bool, err error) {
-	src, err := b.Do(MethodSetChatStickerSet, p)
+	return b.SetChatStickerSetContext(context.Background(), p)
+}
+
+// SetChatStickerSetContext is like SetChatStickerSet but uses ctx for cancellation and deadlines.
+func (b Bot) SetChatStickerSetContext(ctx context.Context, p SetChatStickerSet) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodSetChatStickerSet, p)
 	if err != nil {
 		return ok, err
 	}
@@ -2104,7 +2348,12 @@ func (b Bot) SetChatStickerSet(p SetChatStickerSet) (ok bool, err error) {
 
 // DeleteChatStickerSet delete a group sticker set from a supergroup. The bot must be an administrator in the chat for this to work and must have the appropriate admin rights. Use the field can_set_sticker_set optionally returned in getChat requests to check if the bot can use this method. Returns True on success.
 func (b Bot) DeleteChatStickerSet(p DeleteChatStickerSet) (ok bool, err error) {
-	src, err := b.Do(MethodDeleteChatStickerSet, p)
+	return b.DeleteChatStickerSetContext(context.Background(), p)
+}
+
+// DeleteChatStickerSetContext is like DeleteChatStickerSet but uses ctx for cancellation and deadlines.
+func (b Bot) DeleteChatStickerSetContext(ctx context.Context, p DeleteChatStickerSet) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodDeleteChatStickerSet, p)
 	if err != nil {
 		return ok, err
 	}
@@ -2127,7 +2376,12 @@ func NewAnswerCallback(callbackQueryID string) AnswerCallbackQuery {
 
 // AnswerCallbackQuery send answers to callback queries sent from inline keyboards. The answer will be displayed to the user as a notification at the top of the chat screen or as an alert. On success, True is returned.
 func (b Bot) AnswerCallbackQuery(p AnswerCallbackQuery) (ok bool, err error) {
-	src, err := b.Do(MethodAnswerCallbackQuery, p)
+	return b.AnswerCallbackQueryContext(context.Background(), p)
+}
+
+// AnswerCallbackQueryContext is like AnswerCallbackQuery but uses ctx for cancellation and deadlines.
+func (b Bot) AnswerCallbackQueryContext(ctx context.Context, p AnswerCallbackQuery) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodAnswerCallbackQuery, p)
 	if err != nil {
 		return ok, err
 	}
@@ -2146,7 +2400,12 @@ func (b Bot) AnswerCallbackQuery(p AnswerCallbackQuery) (ok bool, err error) {
 
 // SetMyCommands change the list of the bot's commands. Returns True on success.
 func (b Bot) SetMyCommands(p SetMyCommands) (ok bool, err error) {
-	src, err := b.Do(MethodSetMyCommands, p)
+	return b.SetMyCommandsContext(context.Background(), p)
+}
+
+// SetMyCommandsContext is like SetMyCommands but uses ctx for cancellation and deadlines.
+func (b Bot) SetMyCommandsContext(ctx context.Context, p SetMyCommands) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodSetMyCommands, p)
 	if err != nil {
 		return ok, err
 	}
@@ -2166,7 +2425,12 @@ func (b Bot) SetMyCommands(p SetMyCommands) (ok bool, err error) {
 // DeleteMyCommands delete the list of the bot's commands for the given scope and user language. After deletion, higher
 // level commands will be shown to affected users. Returns True on success.
 func (b Bot) DeleteMyCommands(p DeleteMyCommands) (ok bool, err error) {
-	src, err := b.Do(MethodDeleteMyCommands, p)
+	return b.DeleteMyCommandsContext(context.Background(), p)
+}
+
+// DeleteMyCommandsContext is like DeleteMyCommands but uses ctx for cancellation and deadlines.
+func (b Bot) DeleteMyCommandsContext(ctx context.Context, p DeleteMyCommands) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodDeleteMyCommands, p)
 	if err != nil {
 		return ok, err
 	}
@@ -2186,7 +2450,12 @@ func (b Bot) DeleteMyCommands(p DeleteMyCommands) (ok bool, err error) {
 // GetMyCommands get the current list of the bot's commands. Requires no parameters. Returns Array of BotCommand on
 // success.
 func (b Bot) GetMyCommands(p GetMyCommands) ([]*BotCommand, error) {
-	src, err := b.Do(MethodGetMyCommands, p)
+	return b.GetMyCommandsContext(context.Background(), p)
+}
+
+// GetMyCommandsContext is like GetMyCommands but uses ctx for cancellation and deadlines.
+func (b Bot) GetMyCommandsContext(ctx context.Context, p GetMyCommands) ([]*BotCommand, error) {
+	src, err := b.DoContext(ctx, MethodGetMyCommands, p)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,60 @@
+package telegram_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/toby3d/telegram/v5"
+	"gitlab.com/toby3d/telegram/v5/telegramtest"
+)
+
+func TestSendMediaCaptionEntities(t *testing.T) {
+	srv := telegramtest.NewServer("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+	defer srv.Close()
+
+	bot, err := srv.NewBot()
+	assert.NoError(t, err)
+
+	caption := telegram.NewTextBuilder().Plain("👋 ").Bold("snake_case").Link("site", "https://toby3d.me/")
+	expEntities := []*telegram.MessageEntity{
+		{Type: telegram.EntityBold, Offset: 3, Length: 10},
+		{Type: telegram.EntityTextLink, Offset: 13, Length: 4, URL: "https://toby3d.me/"},
+	}
+
+	for _, tc := range []struct {
+		name string
+		send func() (*telegram.Message, error)
+	}{{
+		name: "photo",
+		send: func() (*telegram.Message, error) {
+			p := telegram.NewPhoto(telegram.ChatID{ID: 42}, &telegram.InputFile{
+				Attachment: strings.NewReader("photo"), Name: "photo.jpg",
+			})
+			p.Caption, p.CaptionEntities = caption.Text(), caption.Entities()
+
+			return bot.SendPhoto(p)
+		},
+	}, {
+		name: "document",
+		send: func() (*telegram.Message, error) {
+			p := telegram.NewDocument(telegram.ChatID{ID: 42}, &telegram.InputFile{
+				Attachment: strings.NewReader("document"), Name: "document.txt",
+			})
+			p.Caption, p.CaptionEntities = caption.Text(), caption.Entities()
+
+			return bot.SendDocument(p)
+		},
+	}} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := tc.send()
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, caption.Text(), m.Caption)
+			assert.Equal(t, expEntities, m.CaptionEntities)
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package telegram
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 /*
 	"crypto/aes"
@@ -531,7 +534,12 @@ var ErrNotEqual = errors.New("credentials hash and credentials data hash is not
 //
 // Use this if the data submitted by the user doesn't satisfy the standards your service requires for any reason. For example, if a birthday date seems invalid, a submitted document is blurry, a scan shows evidence of tampering, etc. Supply some details in the error message to make sure the user knows how to correct the issues.
 func (b Bot) SetPassportDataErrors(uid int64, errors ...PassportElementError) (ok bool, err error) {
-	src, err := b.Do(MethodSetPassportDataErrors, SetPassportDataErrors{
+	return b.SetPassportDataErrorsContext(context.Background(), uid, errors...)
+}
+
+// SetPassportDataErrorsContext is like SetPassportDataErrors but uses ctx for cancellation and deadlines.
+func (b Bot) SetPassportDataErrorsContext(ctx context.Context, uid int64, errors ...PassportElementError) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodSetPassportDataErrors, SetPassportDataErrors{
 		UserID: uid, Errors: errors,
 	})
 	if err != nil {
@@ -1,5 +1,7 @@
 package telegram
 
+import "context"
+
 type (
 	// LabeledPrice represents a portion of the price for goods or services.
 	LabeledPrice struct {
@@ -262,7 +264,12 @@ func NewInvoice(chatID int64, title, description, payload, providerToken, startP
 
 // SendInvoice send invoices. On success, the sent Message is returned.
 func (b Bot) SendInvoice(p SendInvoice) (*Message, error) {
-	src, err := b.Do(MethodSendInvoice, p)
+	return b.SendInvoiceContext(context.Background(), p)
+}
+
+// SendInvoiceContext is like SendInvoice but uses ctx for cancellation and deadlines.
+func (b Bot) SendInvoiceContext(ctx context.Context, p SendInvoice) (*Message, error) {
+	src, err := b.DoContext(ctx, MethodSendInvoice, p)
 	if err != nil {
 		return nil, err
 	}
@@ -286,7 +293,12 @@ func NewAnswerShipping(shippingQueryID string, ok bool) AnswerShippingQuery {
 //
 // If you sent an invoice requesting a shipping address and the parameter is_flexible was specified, the Bot API will send an Update with a shipping_query field to the b. On success, True is returned.
 func (b Bot) AnswerShippingQuery(p AnswerShippingQuery) (ok bool, err error) {
-	src, err := b.Do(MethodAnswerShippingQuery, p)
+	return b.AnswerShippingQueryContext(context.Background(), p)
+}
+
+// AnswerShippingQueryContext is like AnswerShippingQuery but uses ctx for cancellation and deadlines.
+func (b Bot) AnswerShippingQueryContext(ctx context.Context, p AnswerShippingQuery) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodAnswerShippingQuery, p)
 	if err != nil {
 		return false, err
 	}
@@ -311,7 +323,12 @@ func NewAnswerPreCheckout(preCheckoutQueryID string, ok bool) AnswerPreCheckoutQ
 //
 // Note: The Bot API must receive an answer within 10 seconds after the pre-checkout query was sent.
 func (b Bot) AnswerPreCheckoutQuery(p AnswerShippingQuery) (ok bool, err error) {
-	src, err := b.Do(MethodAnswerPreCheckoutQuery, p)
+	return b.AnswerPreCheckoutQueryContext(context.Background(), p)
+}
+
+// AnswerPreCheckoutQueryContext is like AnswerPreCheckoutQuery but uses ctx for cancellation and deadlines.
+func (b Bot) AnswerPreCheckoutQueryContext(ctx context.Context, p AnswerShippingQuery) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodAnswerPreCheckoutQuery, p)
 	if err != nil {
 		return false, err
 	}
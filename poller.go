@@ -0,0 +1,293 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/kirillDanshin/dlog"
+)
+
+type (
+	// Poller receives incoming updates using long polling until it will be stopped.
+	//
+	// Failed getUpdates requests are repeated with exponential backoff and random jitter between MinBackoff and
+	// MaxBackoff. The updates channel is unbuffered and offset of the last update which was received from it is
+	// confirmed on Stop, so updates are neither lost nor duplicated across restarts. Each getUpdates request
+	// confirms all previous updates to Telegram, so received but not handled updates are lost on crash unless the
+	// offset storage is set by SetOffsetStorage.
+	Poller struct {
+		// Delay before the first repeat of the failed getUpdates request.
+		MinBackoff time.Duration
+
+		// Maximum delay between repeats of the failed getUpdates requests.
+		MaxBackoff time.Duration
+
+		bot          *Bot
+		params       GetUpdates
+		errorHandler ErrorHandler
+		offsets      *offsetTracker
+		mu           sync.Mutex
+		offset       int64
+		cancel       context.CancelFunc
+		done         chan struct{}
+	}
+
+	// ErrorHandler is called on each error which can not be returned to the caller, like failed getUpdates
+	// requests while long polling.
+	ErrorHandler func(err error)
+)
+
+const (
+	// DefaultMinBackoff is a default delay before the first repeat of the failed getUpdates request.
+	DefaultMinBackoff time.Duration = time.Second
+
+	// DefaultMaxBackoff is a default maximum delay between repeats of the failed getUpdates requests.
+	DefaultMaxBackoff time.Duration = time.Minute
+)
+
+var (
+	// ErrPollerStarted describes a try to start already started Poller.
+	ErrPollerStarted = errors.New("poller already started")
+
+	// ErrPollerNotStarted describes a try to stop not started or already stopped Poller.
+	ErrPollerNotStarted = errors.New("poller is not started")
+)
+
+// NewPoller creates a new Poller for bot with provided getUpdates parameters. Offset of params is used as the
+// first offset, nil params means 100 updates per request and 60 seconds of long polling timeout.
+func NewPoller(b *Bot, params *GetUpdates) *Poller {
+	if params == nil {
+		params = &GetUpdates{
+			Offset:  0,
+			Limit:   100,
+			Timeout: 60,
+		}
+	}
+
+	return &Poller{
+		MinBackoff: DefaultMinBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+		bot:        b,
+		params:     *params,
+		offset:     params.Offset,
+	}
+}
+
+// SetErrorHandler sets the callback for failed getUpdates requests and offset storage errors, after which polling
+// continues. Without the callback they are only printed in debug logs.
+func (p *Poller) SetErrorHandler(h ErrorHandler) {
+	p.errorHandler = h
+}
+
+// SetOffsetStorage sets the storage of the last processed update identifier, before which all received updates are
+// marked by Done. Updates with the same or lower identifier, like not confirmed before the crash, are skipped
+// without handling. With the storage next updates are requested only after all received updates are marked by
+// Done, so they stay unconfirmed in Telegram until then, and polling stalls if Done is not called.
+func (p *Poller) SetOffsetStorage(s OffsetStorage) {
+	p.offsets = &offsetTracker{storage: s}
+}
+
+// Done marks the update received from the updates channel as processed in the offset storage. Call it after
+// handling of each update, if the storage is set:
+//
+//	for u := range updates {
+//		r.HandleUpdate(ctx, u)
+//		p.Done(ctx, u)
+//	}
+func (p *Poller) Done(ctx context.Context, u *Update) error {
+	if p.offsets == nil || u == nil {
+		return nil
+	}
+
+	return p.offsets.done(ctx, u.ID)
+}
+
+// Offset returns the identifier of the next expected update, i.e. greater by one than the identifier of the last
+// update which was received from the updates channel, or marked by Done if the offset storage is set.
+func (p *Poller) Offset() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.offset
+}
+
+// Start starts polling in the background and returns channel of incoming updates, which is also stored in the
+// Updates field of the bot. Channel will be closed after Stop call.
+func (p *Poller) Start() (UpdatesChannel, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cancel != nil {
+		return nil, ErrPollerStarted
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	// NOTE(toby3d): updates waiting in the buffer would be confirmed on Stop without handling, so each update is
+	// passed directly to the reader.
+	updates := make(UpdatesChannel)
+	p.cancel, p.done = cancel, make(chan struct{})
+	p.bot.Updates = updates
+
+	go p.poll(ctx, updates, p.done)
+
+	return updates, nil
+}
+
+// Stop stops polling, waits until the updates channel will be closed and confirms offset of the last received
+// update. If ctx is done before that, Stop returns ctx error.
+func (p *Poller) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	cancel, done := p.cancel, p.done
+	p.cancel, p.done = nil, nil
+	p.mu.Unlock()
+
+	if cancel == nil {
+		return ErrPollerNotStarted
+	}
+
+	cancel()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-done:
+	}
+
+	offset := p.Offset()
+	if offset <= 0 {
+		return nil
+	}
+
+	// NOTE(toby3d): updates are confirmed by any getUpdates call with the offset higher than their identifiers,
+	// the returned update itself stay unconfirmed.
+	_, err := p.bot.GetUpdatesContext(ctx, &GetUpdates{Offset: offset, Limit: 1})
+
+	return err
+}
+
+func (p *Poller) poll(ctx context.Context, updates UpdatesChannel, done chan struct{}) {
+	defer close(done)
+	defer close(updates)
+
+	for attempt := 0; ; {
+		params := p.params
+		params.Offset = p.Offset()
+
+		result, err := p.bot.GetUpdatesContext(ctx, &params)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			p.errorHandler.handle("Failed to get updates:", err)
+
+			if sleep(ctx, p.backoff(attempt)) != nil {
+				return
+			}
+
+			attempt++
+
+			continue
+		}
+
+		attempt = 0
+
+		for _, update := range result {
+			if update.ID < params.Offset {
+				continue
+			}
+
+			if p.deliver(ctx, updates, update) != nil {
+				return
+			}
+
+			if p.offsets == nil {
+				p.mu.Lock()
+				p.offset = update.ID + 1
+				p.mu.Unlock()
+			}
+		}
+
+		if p.offsets == nil || len(result) == 0 {
+			continue
+		}
+
+		// NOTE(toby3d): the next getUpdates request confirms all returned updates, so wait until they are processed.
+		last := result[len(result)-1].ID
+		if p.offsets.wait(ctx, last) != nil {
+			return
+		}
+
+		p.mu.Lock()
+		if last >= p.offset {
+			p.offset = last + 1
+		}
+		p.mu.Unlock()
+	}
+}
+
+// deliver sends update into the updates channel, unless it's already processed, and returns ctx error if polling
+// is stopped before that.
+func (p *Poller) deliver(ctx context.Context, updates UpdatesChannel, update *Update) error {
+	if p.offsets != nil {
+		// NOTE(toby3d): update is delivered if the storage is unavailable, since it's better than loss.
+		ok, err := p.offsets.begin(ctx, update.ID)
+		if err != nil {
+			p.errorHandler.handle("Failed to check update offset:", err)
+		} else if !ok {
+			dlog.Ln("Polled update is already processed:", update.ID)
+
+			return nil
+		}
+	}
+
+	p.bot.notifyMigration(ctx, update)
+
+	select {
+	case <-ctx.Done():
+		if p.offsets != nil {
+			p.offsets.abort(update.ID)
+		}
+
+		return ctx.Err()
+	case updates <- update:
+		return nil
+	}
+}
+
+// handle passes err to the handler or prints it with prefix in debug logs if there is no handler.
+func (h ErrorHandler) handle(prefix string, err error) {
+	if h == nil {
+		dlog.Ln(prefix, err.Error())
+
+		return
+	}
+
+	h(err)
+}
+
+// backoff returns delay before repeat of the failed request: MinBackoff doubled on each attempt up to MaxBackoff,
+// where second half of delay is random.
+func (p *Poller) backoff(attempt int) time.Duration {
+	d, max := p.MinBackoff, p.MaxBackoff
+	if d <= 0 {
+		d = DefaultMinBackoff
+	}
+
+	if max <= 0 {
+		max = DefaultMaxBackoff
+	}
+
+	for i := 0; i < attempt && d < max; i++ {
+		d *= 2
+	}
+
+	if d > max {
+		d = max
+	}
+
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
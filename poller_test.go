@@ -0,0 +1,151 @@
+package telegram_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/toby3d/telegram/v5"
+	"gitlab.com/toby3d/telegram/v5/telegramtest"
+)
+
+func TestPoller(t *testing.T) {
+	srv := telegramtest.NewServer("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+	defer srv.Close()
+
+	bot, err := srv.NewBot()
+	assert.NoError(t, err)
+
+	for _, text := range []string{"/start", "hello", "world"} {
+		_, err = srv.AddMessage(42, &telegram.User{ID: 42, FirstName: "Maxim"}, text)
+		assert.NoError(t, err)
+	}
+
+	p := telegram.NewPoller(bot, &telegram.GetUpdates{Timeout: 1})
+
+	updates, err := p.Start()
+	assert.NoError(t, err)
+
+	_, err = p.Start()
+	assert.True(t, errors.Is(err, telegram.ErrPollerStarted))
+
+	first := <-updates
+	assert.Equal(t, "/start", first.Message.Text)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.NoError(t, p.Stop(ctx))
+
+	// NOTE(toby3d): other fetched updates are not received, so they must stay unconfirmed.
+	_, ok := <-updates
+	assert.False(t, ok)
+	assert.Equal(t, first.ID+1, p.Offset())
+	assert.Equal(t, 2, srv.WebhookInfo().PendingUpdateCount)
+	assert.True(t, errors.Is(p.Stop(ctx), telegram.ErrPollerNotStarted))
+}
+
+func TestPollerBackoff(t *testing.T) {
+	srv := telegramtest.NewServer("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+	defer srv.Close()
+
+	bot, err := srv.NewBot()
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		srv.AddError(telegram.MethodGetUpdates, telegram.Error{
+			Code:        http.StatusBadGateway,
+			Description: "Bad Gateway",
+		})
+	}
+
+	_, err = srv.AddMessage(42, &telegram.User{ID: 42, FirstName: "Maxim"}, "hello")
+	assert.NoError(t, err)
+
+	var (
+		mu     sync.Mutex
+		errs   []error
+		failed []time.Time
+	)
+
+	p := telegram.NewPoller(bot, &telegram.GetUpdates{Timeout: 1})
+	p.MinBackoff, p.MaxBackoff = 20*time.Millisecond, time.Second
+	p.SetErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		errs = append(errs, err)
+		failed = append(failed, time.Now())
+	})
+
+	updates, err := p.Start()
+	assert.NoError(t, err)
+
+	update := <-updates
+	assert.Equal(t, "hello", update.Message.Text)
+	assert.NoError(t, p.Stop(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if assert.Len(t, errs, 2) {
+		var tgErr *telegram.Error
+		assert.True(t, errors.As(errs[0], &tgErr))
+		assert.Equal(t, http.StatusBadGateway, tgErr.Code)
+
+		// NOTE(toby3d): the first delay contains random jitter in the second half of MinBackoff.
+		assert.True(t, failed[1].Sub(failed[0]) >= 10*time.Millisecond)
+	}
+}
+
+func TestPollerOffsetStorage(t *testing.T) {
+	srv := telegramtest.NewServer("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+	defer srv.Close()
+
+	bot, err := srv.NewBot()
+	assert.NoError(t, err)
+
+	for _, text := range []string{"first", "second", "third", "fourth"} {
+		_, err = srv.AddMessage(42, &telegram.User{ID: 42, FirstName: "Maxim"}, text)
+		assert.NoError(t, err)
+	}
+
+	// NOTE(toby3d): the previous process is crashed after handling the second update without confirmation.
+	storage := telegram.NewMemoryStorage()
+	assert.NoError(t, storage.SetLastUpdateID(context.Background(), 2))
+
+	p := telegram.NewPoller(bot, &telegram.GetUpdates{Timeout: 1})
+	p.SetOffsetStorage(storage)
+
+	updates, err := p.Start()
+	assert.NoError(t, err)
+
+	third, fourth := <-updates, <-updates
+	assert.Equal(t, "third", third.Message.Text)
+	assert.Equal(t, "fourth", fourth.Message.Text)
+
+	// NOTE(toby3d): updates are handled out of order, like by Dispatcher.
+	assert.NoError(t, p.Done(context.Background(), fourth))
+
+	last, err := storage.GetLastUpdateID(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), last, "the third update is still handling")
+	assert.Equal(t, 4, srv.WebhookInfo().PendingUpdateCount, "updates are not confirmed before handling")
+
+	assert.NoError(t, p.Done(context.Background(), third))
+
+	for i := 0; i < 100 && p.Offset() != fourth.ID+1; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.NoError(t, p.Stop(context.Background()))
+	assert.Zero(t, srv.WebhookInfo().PendingUpdateCount)
+
+	last, err = storage.GetLastUpdateID(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, fourth.ID, last)
+}
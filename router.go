@@ -0,0 +1,232 @@
+package telegram
+
+import "context"
+
+type (
+	// Router dispatches incoming updates to the first registered handler which is matched by the update kind and
+	// all of its filters.
+	Router struct {
+		routes       []route
+		errorHandler ErrorHandler
+	}
+
+	route struct {
+		kind    UpdateKind // NOTE(toby3d): KindUnknown matches updates of any kind
+		filters []Filter
+		handler Handler
+	}
+
+	// Handler handles the update of any kind.
+	Handler func(ctx context.Context, u *Update) error
+
+	// MessageHandler handles new or edited messages and channel posts.
+	MessageHandler func(ctx context.Context, m *Message) error
+
+	// InlineQueryHandler handles inline queries.
+	InlineQueryHandler func(ctx context.Context, q *InlineQuery) error
+
+	// ChosenInlineResultHandler handles chosen inline results.
+	ChosenInlineResultHandler func(ctx context.Context, r *ChosenInlineResult) error
+
+	// CallbackQueryHandler handles callback queries.
+	CallbackQueryHandler func(ctx context.Context, q *CallbackQuery) error
+
+	// ShippingQueryHandler handles shipping queries.
+	ShippingQueryHandler func(ctx context.Context, q *ShippingQuery) error
+
+	// PreCheckoutQueryHandler handles pre-checkout queries.
+	PreCheckoutQueryHandler func(ctx context.Context, q *PreCheckoutQuery) error
+
+	// PollHandler handles poll states.
+	PollHandler func(ctx context.Context, p *Poll) error
+
+	// PollAnswerHandler handles answers in non-anonymous polls.
+	PollAnswerHandler func(ctx context.Context, a *PollAnswer) error
+
+	// ChatMemberHandler handles chat member status changes.
+	ChatMemberHandler func(ctx context.Context, m *ChatMemberUpdated) error
+)
+
+// NewRouter creates a new Router without routes.
+func NewRouter() *Router {
+	return new(Router)
+}
+
+// SetErrorHandler sets the callback for errors returned by handlers in Listen, HandleUpdate returns them to the
+// caller instead. Without the callback errors of Listen are only printed in debug logs.
+func (r *Router) SetErrorHandler(h ErrorHandler) {
+	r.errorHandler = h
+}
+
+// OnUpdate registers handler for updates of any kind.
+func (r *Router) OnUpdate(h Handler, filters ...Filter) {
+	r.handle(KindUnknown, filters, h)
+}
+
+// OnMessage registers handler for new incoming messages.
+func (r *Router) OnMessage(h MessageHandler, filters ...Filter) {
+	r.handle(KindMessage, filters, func(ctx context.Context, u *Update) error {
+		return h(ctx, u.Message)
+	})
+}
+
+// OnEditedMessage registers handler for edited messages.
+func (r *Router) OnEditedMessage(h MessageHandler, filters ...Filter) {
+	r.handle(KindEditedMessage, filters, func(ctx context.Context, u *Update) error {
+		return h(ctx, u.EditedMessage)
+	})
+}
+
+// OnChannelPost registers handler for new incoming channel posts.
+func (r *Router) OnChannelPost(h MessageHandler, filters ...Filter) {
+	r.handle(KindChannelPost, filters, func(ctx context.Context, u *Update) error {
+		return h(ctx, u.ChannelPost)
+	})
+}
+
+// OnEditedChannelPost registers handler for edited channel posts.
+func (r *Router) OnEditedChannelPost(h MessageHandler, filters ...Filter) {
+	r.handle(KindEditedChannelPost, filters, func(ctx context.Context, u *Update) error {
+		return h(ctx, u.EditedChannelPost)
+	})
+}
+
+// OnInlineQuery registers handler for inline queries.
+func (r *Router) OnInlineQuery(h InlineQueryHandler, filters ...Filter) {
+	r.handle(KindInlineQuery, filters, func(ctx context.Context, u *Update) error {
+		return h(ctx, u.InlineQuery)
+	})
+}
+
+// OnChosenInlineResult registers handler for chosen inline results.
+func (r *Router) OnChosenInlineResult(h ChosenInlineResultHandler, filters ...Filter) {
+	r.handle(KindChosenInlineResult, filters, func(ctx context.Context, u *Update) error {
+		return h(ctx, u.ChosenInlineResult)
+	})
+}
+
+// OnCallbackQuery registers handler for callback queries.
+func (r *Router) OnCallbackQuery(h CallbackQueryHandler, filters ...Filter) {
+	r.handle(KindCallbackQuery, filters, func(ctx context.Context, u *Update) error {
+		return h(ctx, u.CallbackQuery)
+	})
+}
+
+// OnShippingQuery registers handler for shipping queries.
+func (r *Router) OnShippingQuery(h ShippingQueryHandler, filters ...Filter) {
+	r.handle(KindShippingQuery, filters, func(ctx context.Context, u *Update) error {
+		return h(ctx, u.ShippingQuery)
+	})
+}
+
+// OnPreCheckoutQuery registers handler for pre-checkout queries.
+func (r *Router) OnPreCheckoutQuery(h PreCheckoutQueryHandler, filters ...Filter) {
+	r.handle(KindPreCheckoutQuery, filters, func(ctx context.Context, u *Update) error {
+		return h(ctx, u.PreCheckoutQuery)
+	})
+}
+
+// OnPoll registers handler for poll states.
+func (r *Router) OnPoll(h PollHandler, filters ...Filter) {
+	r.handle(KindPoll, filters, func(ctx context.Context, u *Update) error {
+		return h(ctx, u.Poll)
+	})
+}
+
+// OnPollAnswer registers handler for answers in non-anonymous polls.
+func (r *Router) OnPollAnswer(h PollAnswerHandler, filters ...Filter) {
+	r.handle(KindPollAnswer, filters, func(ctx context.Context, u *Update) error {
+		return h(ctx, u.PollAnswer)
+	})
+}
+
+// OnMyChatMember registers handler for status changes of the bot itself.
+func (r *Router) OnMyChatMember(h ChatMemberHandler, filters ...Filter) {
+	r.handle(KindMyChatMember, filters, func(ctx context.Context, u *Update) error {
+		return h(ctx, u.MyChatMember)
+	})
+}
+
+// OnChatMember registers handler for status changes of chat members.
+func (r *Router) OnChatMember(h ChatMemberHandler, filters ...Filter) {
+	r.handle(KindChatMember, filters, func(ctx context.Context, u *Update) error {
+		return h(ctx, u.ChatMember)
+	})
+}
+
+// HandleUpdate calls the first matched handler for u and returns its error. Update without matched handlers is
+// ignored.
+func (r *Router) HandleUpdate(ctx context.Context, u *Update) error {
+	for _, rt := range r.routes {
+		if rt.matches(u) {
+			return rt.handler(ctx, u)
+		}
+	}
+
+	return nil
+}
+
+// AllowedUpdates returns names of update kinds which have registered handlers, for AllowedUpdates parameter of
+// GetUpdates and SetWebhook. If the router has handlers for updates of any kind, then all kinds are returned, since
+// Telegram does not send chat_member updates by default.
+func (r *Router) AllowedUpdates() []string {
+	var kinds [len(updateKinds)]bool
+
+	for _, rt := range r.routes {
+		if rt.kind != KindUnknown {
+			kinds[rt.kind] = true
+
+			continue
+		}
+
+		for kind := range kinds {
+			kinds[kind] = true
+		}
+	}
+
+	result := make([]string, 0, len(kinds))
+
+	for kind, ok := range kinds {
+		if ok && UpdateKind(kind) != KindUnknown {
+			result = append(result, UpdateKind(kind).String())
+		}
+	}
+
+	return result
+}
+
+// Listen handles updates from channel one by one until it will be closed or ctx is done.
+func (r *Router) Listen(ctx context.Context, updates UpdatesChannel) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case u, ok := <-updates:
+			if !ok {
+				return
+			}
+
+			if err := r.HandleUpdate(ctx, u); err != nil {
+				r.errorHandler.handle("Failed to handle update:", err)
+			}
+		}
+	}
+}
+
+func (r *Router) handle(kind UpdateKind, filters []Filter, h Handler) {
+	r.routes = append(r.routes, route{kind: kind, filters: filters, handler: h})
+}
+
+func (rt route) matches(u *Update) bool {
+	if rt.kind != KindUnknown && rt.kind != u.Kind() {
+		return false
+	}
+
+	for _, f := range rt.filters {
+		if !f(u) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,96 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterHandleUpdate(t *testing.T) {
+	var called []string
+
+	r := NewRouter()
+	r.OnMessage(func(ctx context.Context, m *Message) error {
+		called = append(called, "start")
+
+		return nil
+	}, Command("start"))
+	r.OnMessage(func(ctx context.Context, m *Message) error {
+		called = append(called, "message:"+m.Text)
+
+		return nil
+	})
+	r.OnCallbackQuery(func(ctx context.Context, q *CallbackQuery) error {
+		called = append(called, "callback:"+q.Data)
+
+		return nil
+	}, CallbackPrefix("vote:"))
+	r.OnPollAnswer(func(ctx context.Context, a *PollAnswer) error {
+		called = append(called, "answer:"+a.PollID)
+
+		return nil
+	})
+	r.OnMyChatMember(func(ctx context.Context, m *ChatMemberUpdated) error {
+		called = append(called, "my member")
+
+		return nil
+	})
+	r.OnChatMember(func(ctx context.Context, m *ChatMemberUpdated) error {
+		called = append(called, "member")
+
+		return nil
+	})
+
+	for _, u := range []*Update{
+		{Message: &Message{Text: "/start", Entities: []*MessageEntity{{Type: EntityBotCommand, Length: 6}}}},
+		{Message: &Message{Text: "hello"}},
+		{EditedMessage: &Message{Text: "hello"}},
+		{CallbackQuery: &CallbackQuery{Data: "vote:42"}},
+		{CallbackQuery: &CallbackQuery{Data: "page:2"}},
+		{PollAnswer: &PollAnswer{PollID: "abc"}},
+		{MyChatMember: &ChatMemberUpdated{}},
+		{ChatMember: &ChatMemberUpdated{}},
+	} {
+		assert.NoError(t, r.HandleUpdate(context.Background(), u))
+	}
+
+	assert.Equal(t, []string{"start", "message:hello", "callback:vote:42", "answer:abc", "my member", "member"},
+		called)
+}
+
+func TestRouterListen(t *testing.T) {
+	errTest := errors.New("test")
+
+	var errs []error
+
+	r := NewRouter()
+	r.SetErrorHandler(func(err error) { errs = append(errs, err) })
+	r.OnInlineQuery(func(ctx context.Context, q *InlineQuery) error { return errTest })
+	r.OnUpdate(func(ctx context.Context, u *Update) error { return nil })
+
+	updates := make(UpdatesChannel, 3)
+	updates <- &Update{InlineQuery: &InlineQuery{Query: "abc"}}
+	updates <- &Update{Poll: &Poll{ID: "abc"}}
+	updates <- &Update{InlineQuery: &InlineQuery{Query: "def"}}
+	close(updates)
+
+	r.Listen(context.Background(), updates)
+	assert.Equal(t, []error{errTest, errTest}, errs)
+}
+
+func TestRouterAllowedUpdates(t *testing.T) {
+	r := NewRouter()
+	assert.Empty(t, r.AllowedUpdates())
+
+	r.OnCallbackQuery(func(ctx context.Context, q *CallbackQuery) error { return nil })
+	r.OnMessage(func(ctx context.Context, m *Message) error { return nil }, Command("start"))
+	r.OnMessage(func(ctx context.Context, m *Message) error { return nil })
+	r.OnChatMember(func(ctx context.Context, m *ChatMemberUpdated) error { return nil })
+	assert.Equal(t, []string{UpdateMessage, UpdateCallbackQuery, UpdateChatMember}, r.AllowedUpdates())
+
+	r.OnUpdate(func(ctx context.Context, u *Update) error { return nil })
+	assert.Len(t, r.AllowedUpdates(), int(KindChatMember))
+	assert.Contains(t, r.AllowedUpdates(), UpdateMyChatMember)
+}
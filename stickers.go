@@ -1,6 +1,7 @@
 package telegram
 
 import (
+	"context"
 	"strconv"
 	"strings"
 )
@@ -200,7 +201,12 @@ func NewSticker(chatID ChatID, sticker *InputFile) SendSticker {
 
 // SendSticker send .webp stickers. On success, the sent Message is returned.
 func (b Bot) SendSticker(p SendSticker) (*Message, error) {
-	src, err := b.Do(MethodSendSticker, p)
+	return b.SendStickerContext(context.Background(), p)
+}
+
+// SendStickerContext is like SendSticker but uses ctx for cancellation and deadlines.
+func (b Bot) SendStickerContext(ctx context.Context, p SendSticker) (*Message, error) {
+	src, err := b.DoContext(ctx, MethodSendSticker, p)
 	if err != nil {
 		return nil, err
 	}
@@ -215,7 +221,12 @@ func (b Bot) SendSticker(p SendSticker) (*Message, error) {
 
 // GetStickerSet get a sticker set. On success, a StickerSet object is returned.
 func (b Bot) GetStickerSet(name string) (*StickerSet, error) {
-	src, err := b.Do(MethodGetStickerSet, GetStickerSet{Name: name})
+	return b.GetStickerSetContext(context.Background(), name)
+}
+
+// GetStickerSetContext is like GetStickerSet but uses ctx for cancellation and deadlines.
+func (b Bot) GetStickerSetContext(ctx context.Context, name string) (*StickerSet, error) {
+	src, err := b.DoContext(ctx, MethodGetStickerSet, GetStickerSet{Name: name})
 	if err != nil {
 		return nil, err
 	}
@@ -230,15 +241,17 @@ func (b Bot) GetStickerSet(name string) (*StickerSet, error) {
 
 // UploadStickerFile upload a .png file with a sticker for later use in createNewStickerSet and addStickerToSet methods (can be used multiple times). Returns the uploaded File on success.
 func (b Bot) UploadStickerFile(uid int, sticker *InputFile) (*File, error) {
+	return b.UploadStickerFileContext(context.Background(), uid, sticker)
+}
+
+// UploadStickerFileContext is like UploadStickerFile but uses ctx for cancellation and deadlines.
+func (b Bot) UploadStickerFileContext(ctx context.Context, uid int, sticker *InputFile) (*File, error) {
 	params := make(map[string]string)
 	params["user_id"] = strconv.Itoa(uid)
 
-	var err error
-	if params["png_sticker"], err = b.marshler.MarshalToString(sticker); err != nil {
-		return nil, err
-	}
+	params["png_sticker"] = sticker.formValue()
 
-	src, err := b.Upload(MethodUploadStickerFile, params, sticker)
+	src, err := b.UploadContext(ctx, MethodUploadStickerFile, params, sticker)
 	if err != nil {
 		return nil, err
 	}
@@ -263,6 +276,11 @@ func NewStickerSet(userID int64, name, title string, pngSticker *InputFile, emoj
 
 // CreateNewStickerSet create new sticker set owned by a user. The bot will be able to edit the created sticker set. Returns True on success.
 func (b *Bot) CreateNewStickerSet(p CreateNewStickerSet) (ok bool, err error) {
+	return b.CreateNewStickerSetContext(context.Background(), p)
+}
+
+// CreateNewStickerSetContext is like CreateNewStickerSet but uses ctx for cancellation and deadlines.
+func (b *Bot) CreateNewStickerSetContext(ctx context.Context, p CreateNewStickerSet) (ok bool, err error) {
 	params := make(map[string]string)
 	params["user_id"] = strconv.FormatInt(p.UserID, 10)
 	params["name"] = p.Name
@@ -270,9 +288,7 @@ func (b *Bot) CreateNewStickerSet(p CreateNewStickerSet) (ok bool, err error) {
 	params["emojis"] = p.Emojis
 	params["contains_masks"] = strconv.FormatBool(p.ContainsMasks)
 
-	if params["png_sticker"], err = b.marshler.MarshalToString(p.PNGSticker); err != nil {
-		return
-	}
+	params["png_sticker"] = p.PNGSticker.formValue()
 
 	if params["mask_position"], err = b.marshler.MarshalToString(p.MaskPosition); err != nil {
 		return
@@ -283,7 +299,7 @@ func (b *Bot) CreateNewStickerSet(p CreateNewStickerSet) (ok bool, err error) {
 		files = append(files, p.PNGSticker)
 	}
 
-	src, err := b.Upload(MethodCreateNewStickerSet, params, files...)
+	src, err := b.UploadContext(ctx, MethodCreateNewStickerSet, params, files...)
 	if err != nil {
 		return ok, err
 	}
@@ -297,14 +313,17 @@ func (b *Bot) CreateNewStickerSet(p CreateNewStickerSet) (ok bool, err error) {
 
 // AddStickerToSet add a new sticker to a set created by the b. Returns True on success.
 func (b *Bot) AddStickerToSet(p AddStickerToSet) (ok bool, err error) {
+	return b.AddStickerToSetContext(context.Background(), p)
+}
+
+// AddStickerToSetContext is like AddStickerToSet but uses ctx for cancellation and deadlines.
+func (b *Bot) AddStickerToSetContext(ctx context.Context, p AddStickerToSet) (ok bool, err error) {
 	params := make(map[string]string)
 	params["user_id"] = strconv.FormatInt(p.UserID, 10)
 	params["name"] = p.Name
 	params["emojis"] = p.Emojis
 
-	if params["png_sticker"], err = b.marshler.MarshalToString(p.PNGSticker); err != nil {
-		return
-	}
+	params["png_sticker"] = p.PNGSticker.formValue()
 
 	if params["mask_position"], err = b.marshler.MarshalToString(p.MaskPosition); err != nil {
 		return
@@ -315,7 +334,7 @@ func (b *Bot) AddStickerToSet(p AddStickerToSet) (ok bool, err error) {
 		files = append(files, p.PNGSticker)
 	}
 
-	src, err := b.Upload(MethodAddStickerToSet, params, files...)
+	src, err := b.UploadContext(ctx, MethodAddStickerToSet, params, files...)
 	if err != nil {
 		return ok, err
 	}
@@ -329,7 +348,12 @@ func (b *Bot) AddStickerToSet(p AddStickerToSet) (ok bool, err error) {
 
 // SetStickerPositionInSet move a sticker in a set created by the bot to a specific position. Returns True on success.
 func (b *Bot) SetStickerPositionInSet(sticker string, position int) (ok bool, err error) {
-	src, err := b.marshler.Marshal(&SetStickerPositionInSet{
+	return b.SetStickerPositionInSetContext(context.Background(), sticker, position)
+}
+
+// SetStickerPositionInSetContext is like SetStickerPositionInSet but uses ctx for cancellation and deadlines.
+func (b *Bot) SetStickerPositionInSetContext(ctx context.Context, sticker string, position int) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodSetStickerPositionInSet, SetStickerPositionInSet{
 		Sticker:  sticker,
 		Position: position,
 	})
@@ -346,7 +370,12 @@ func (b *Bot) SetStickerPositionInSet(sticker string, position int) (ok bool, er
 
 // DeleteStickerFromSet delete a sticker from a set created by the b. Returns True on success.
 func (b *Bot) DeleteStickerFromSet(sticker string) (ok bool, err error) {
-	src, err := b.Do(MethodDeleteStickerFromSet, DeleteStickerFromSet{Sticker: sticker})
+	return b.DeleteStickerFromSetContext(context.Background(), sticker)
+}
+
+// DeleteStickerFromSetContext is like DeleteStickerFromSet but uses ctx for cancellation and deadlines.
+func (b *Bot) DeleteStickerFromSetContext(ctx context.Context, sticker string) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodDeleteStickerFromSet, DeleteStickerFromSet{Sticker: sticker})
 	if err != nil {
 		return ok, err
 	}
@@ -361,20 +390,23 @@ func (b *Bot) DeleteStickerFromSet(sticker string) (ok bool, err error) {
 // SetStickerSetThumb set the thumbnail of a sticker set. Animated thumbnails can be set for animated sticker sets
 // only. Returns True on success.
 func (b *Bot) SetStickerSetThumb(p SetStickerSetThumb) (ok bool, err error) {
+	return b.SetStickerSetThumbContext(context.Background(), p)
+}
+
+// SetStickerSetThumbContext is like SetStickerSetThumb but uses ctx for cancellation and deadlines.
+func (b *Bot) SetStickerSetThumbContext(ctx context.Context, p SetStickerSetThumb) (ok bool, err error) {
 	params := make(map[string]string)
 	params["name"] = p.Name
 	params["user_id"] = strconv.FormatInt(p.UserID, 10)
 
-	if params["thumb"], err = b.marshler.MarshalToString(p.Thumb); err != nil {
-		return
-	}
+	params["thumb"] = p.Thumb.formValue()
 
 	files := make([]*InputFile, 0)
 	if p.Thumb.IsAttachment() {
 		files = append(files, p.Thumb)
 	}
 
-	src, err := b.Upload(MethodSetStickerSetThumb, params, files...)
+	src, err := b.UploadContext(ctx, MethodSetStickerSetThumb, params, files...)
 	if err != nil {
 		return ok, err
 	}
@@ -0,0 +1,487 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type (
+	// StateKey identifies a conversation of the user in the chat.
+	StateKey struct {
+		ChatID int64 `json:"chat_id"`
+		UserID int64 `json:"user_id"`
+	}
+
+	// State represents a stored step of the conversation.
+	State struct {
+		// Name of the current state
+		Name string `json:"name"`
+
+		// Values collected on the previous steps
+		Data map[string]string `json:"data,omitempty"`
+
+		// Time after which the conversation is expired, zero if it never expires
+		Expires time.Time `json:"expires"`
+	}
+
+	// StateStorage stores states of conversations.
+	StateStorage interface {
+		// GetState returns the state stored by key or nil if there is no state.
+		GetState(ctx context.Context, key StateKey) (*State, error)
+
+		// SetState stores the state by key.
+		SetState(ctx context.Context, key StateKey, state *State) error
+
+		// DeleteState removes the state stored by key, if any.
+		DeleteState(ctx context.Context, key StateKey) error
+	}
+
+	// StateCleaner is an optional interface of StateStorage which removes all expired states at once, so states of
+	// abandoned conversations do not pile up in the storage.
+	StateCleaner interface {
+		// DeleteExpiredStates removes states which are expired at the now time.
+		DeleteExpiredStates(ctx context.Context, now time.Time) error
+	}
+
+	// OffsetStorage stores identifier of the last processed update, so Poller and Webhook can skip updates which
+	// are already processed before restart or redelivered by Telegram.
+	OffsetStorage interface {
+		// GetLastUpdateID returns identifier of the last processed update or zero if there is no one.
+		GetLastUpdateID(ctx context.Context) (int64, error)
+
+		// SetLastUpdateID stores identifier of the last processed update.
+		SetLastUpdateID(ctx context.Context, id int64) error
+	}
+
+	// MemoryStorage is a StateStorage, StateCleaner and OffsetStorage which keeps data in memory until restart.
+	MemoryStorage struct {
+		mu           sync.RWMutex
+		states       map[StateKey]*State
+		lastUpdateID int64
+	}
+
+	// FileStorage is a StateStorage, StateCleaner and OffsetStorage which keeps data in memory and saves it into the
+	// JSON file on each change, so it survive restarts.
+	FileStorage struct {
+		MemoryStorage
+		path   string
+		saveMu sync.Mutex
+	}
+
+	fileStorage struct {
+		LastUpdateID int64       `json:"last_update_id,omitempty"`
+		States       []fileState `json:"states"`
+	}
+
+	fileState struct {
+		Key   StateKey `json:"key"`
+		State *State   `json:"state"`
+	}
+
+	// offsetTracker skips already processed or handling updates using OffsetStorage and the bounded set of recent
+	// identifiers. Updates can be processed out of order, like by Dispatcher, so only the identifier before which
+	// all started updates are processed is stored.
+	offsetTracker struct {
+		storage OffsetStorage
+		mu      sync.Mutex
+		last    int64
+		loaded  bool
+		recent  map[int64]bool
+		order   []int64
+		changed chan struct{}
+	}
+)
+
+const (
+	// recentUpdatesLimit is a number of the last processed updates which are remembered by offsetTracker.
+	recentUpdatesLimit int = 1000
+
+	// updateIDResetGap is a distance below the stored identifier from which the update is considered as the start
+	// of the new sequence: after a week without updates Telegram chooses the next identifier randomly.
+	updateIDResetGap int64 = 1 << 20
+)
+
+// NewMemoryStorage creates a new empty MemoryStorage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{states: make(map[StateKey]*State)}
+}
+
+// GetState implements StateStorage interface.
+func (s *MemoryStorage) GetState(_ context.Context, key StateKey) (*State, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, ok := s.states[key]
+	if !ok {
+		return nil, nil
+	}
+
+	return state.copy(), nil
+}
+
+// SetState implements StateStorage interface.
+func (s *MemoryStorage) SetState(_ context.Context, key StateKey, state *State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.states == nil {
+		s.states = make(map[StateKey]*State)
+	}
+
+	s.states[key] = state.copy()
+
+	return nil
+}
+
+// DeleteState implements StateStorage interface.
+func (s *MemoryStorage) DeleteState(_ context.Context, key StateKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.states, key)
+
+	return nil
+}
+
+// DeleteExpiredStates implements StateCleaner interface.
+func (s *MemoryStorage) DeleteExpiredStates(_ context.Context, now time.Time) error {
+	s.deleteExpiredStates(now)
+
+	return nil
+}
+
+// deleteExpiredStates removes states which are expired at now and returns their number.
+func (s *MemoryStorage) deleteExpiredStates(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+
+	for key, state := range s.states {
+		if state.IsExpired(now) {
+			delete(s.states, key)
+			n++
+		}
+	}
+
+	return n
+}
+
+// GetLastUpdateID implements OffsetStorage interface.
+func (s *MemoryStorage) GetLastUpdateID(_ context.Context) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.lastUpdateID, nil
+}
+
+// SetLastUpdateID implements OffsetStorage interface.
+func (s *MemoryStorage) SetLastUpdateID(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastUpdateID = id
+
+	return nil
+}
+
+// NewFileStorage creates a new FileStorage with data loaded from the file by path, if it's exists. Use separate
+// files for different bots.
+func NewFileStorage(path string) (*FileStorage, error) {
+	s := &FileStorage{MemoryStorage: *NewMemoryStorage(), path: path}
+
+	src, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var data fileStorage
+	if err = json.Unmarshal(src, &data); err != nil {
+		return nil, err
+	}
+
+	s.lastUpdateID = data.LastUpdateID
+
+	for _, state := range data.States {
+		s.states[state.Key] = state.State
+	}
+
+	return s, nil
+}
+
+// SetState implements StateStorage interface.
+func (s *FileStorage) SetState(ctx context.Context, key StateKey, state *State) error {
+	if err := s.MemoryStorage.SetState(ctx, key, state); err != nil {
+		return err
+	}
+
+	return s.save()
+}
+
+// DeleteState implements StateStorage interface.
+func (s *FileStorage) DeleteState(ctx context.Context, key StateKey) error {
+	if err := s.MemoryStorage.DeleteState(ctx, key); err != nil {
+		return err
+	}
+
+	return s.save()
+}
+
+// DeleteExpiredStates implements StateCleaner interface.
+func (s *FileStorage) DeleteExpiredStates(_ context.Context, now time.Time) error {
+	if s.deleteExpiredStates(now) == 0 {
+		return nil
+	}
+
+	return s.save()
+}
+
+// SetLastUpdateID implements OffsetStorage interface.
+func (s *FileStorage) SetLastUpdateID(ctx context.Context, id int64) error {
+	if err := s.MemoryStorage.SetLastUpdateID(ctx, id); err != nil {
+		return err
+	}
+
+	return s.save()
+}
+
+// save rewrites the file with all data.
+func (s *FileStorage) save() error {
+	// NOTE(toby3d): snapshot and write are serialized, so the older snapshot never replaces the newer one.
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.RLock()
+	data := fileStorage{
+		LastUpdateID: s.lastUpdateID,
+		States:       make([]fileState, 0, len(s.states)),
+	}
+
+	for key, state := range s.states {
+		data.States = append(data.States, fileState{Key: key, State: state})
+	}
+
+	src, err := json.Marshal(data)
+	s.mu.RUnlock()
+
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.path, src)
+}
+
+// writeFileAtomic writes data into the temporary file and renames it to path, so the file is never left half
+// written after crash.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(f.Name())
+
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// IsExpired checks that the state is expired at the time now.
+func (s State) IsExpired(now time.Time) bool {
+	return !s.Expires.IsZero() && !now.Before(s.Expires)
+}
+
+func (s *State) copy() *State {
+	if s == nil {
+		return nil
+	}
+
+	result := *s
+
+	if s.Data != nil {
+		result.Data = make(map[string]string, len(s.Data))
+
+		for k, v := range s.Data {
+			result.Data[k] = v
+		}
+	}
+
+	return &result
+}
+
+// begin marks the update with id as handling and returns false if it's already processed or handling, so
+// redelivered updates are handled once.
+func (t *offsetTracker) begin(ctx context.Context, id int64) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.load(ctx); err != nil {
+		return false, err
+	}
+
+	// NOTE(toby3d): the stored identifier is ignored for the new sequence of identifiers.
+	if id < t.last && t.last-id >= updateIDResetGap {
+		t.last, t.recent, t.order = 0, nil, nil
+	}
+
+	if _, ok := t.recent[id]; ok || id <= t.last {
+		return false, nil
+	}
+
+	if t.recent == nil {
+		t.recent = make(map[int64]bool)
+	}
+
+	t.recent[id] = false
+	t.order = append(t.order, id)
+
+	return true, nil
+}
+
+// abort unmarks the handling update with id, which is not accepted, so it can be handled after redelivery.
+func (t *offsetTracker) abort(id int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if processed, ok := t.recent[id]; !ok || processed {
+		return
+	}
+
+	delete(t.recent, id)
+
+	for i := range t.order {
+		if t.order[i] == id {
+			t.order = append(t.order[:i], t.order[i+1:]...)
+
+			break
+		}
+	}
+
+	t.advance()
+}
+
+// done marks the update with id as processed and stores the identifier before which all started updates are
+// processed, if it's changed.
+func (t *offsetTracker) done(ctx context.Context, id int64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.load(ctx); err != nil {
+		return err
+	}
+
+	if processed, ok := t.recent[id]; !ok || processed {
+		return nil
+	}
+
+	t.recent[id] = true
+
+	if !t.advance() {
+		return nil
+	}
+
+	// NOTE(toby3d): stored identifier may fall behind on failure, which only repeats processed updates after
+	// restart.
+	return t.storage.SetLastUpdateID(ctx, t.last)
+}
+
+// wait blocks until the update with id and all previous started updates are processed or aborted.
+func (t *offsetTracker) wait(ctx context.Context, id int64) error {
+	for {
+		t.mu.Lock()
+		if t.last >= id || t.idle(id) {
+			t.mu.Unlock()
+
+			return nil
+		}
+
+		if t.changed == nil {
+			t.changed = make(chan struct{})
+		}
+
+		changed := t.changed
+		t.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// idle checks that there are no handling updates with identifiers up to id.
+func (t *offsetTracker) idle(id int64) bool {
+	for recentID, processed := range t.recent {
+		if !processed && recentID <= id {
+			return false
+		}
+	}
+
+	return true
+}
+
+// advance moves the last identifier up to the lowest handling update, forgets the oldest processed updates over
+// the limit and wakes up waiters. It returns false if the last identifier is not changed.
+func (t *offsetTracker) advance() bool {
+	last, handling := t.last, int64(math.MaxInt64)
+
+	for id, processed := range t.recent {
+		if !processed && id < handling {
+			handling = id
+		}
+	}
+
+	for id, processed := range t.recent {
+		if processed && id < handling && id > t.last {
+			t.last = id
+		}
+	}
+
+	// NOTE(toby3d): processed updates above the last identifier are kept, since only they skip redeliveries.
+	for len(t.order) > recentUpdatesLimit && t.recent[t.order[0]] && t.order[0] <= t.last {
+		delete(t.recent, t.order[0])
+		t.order = t.order[1:]
+	}
+
+	if t.changed != nil {
+		close(t.changed)
+		t.changed = nil
+	}
+
+	return t.last != last
+}
+
+func (t *offsetTracker) load(ctx context.Context) (err error) {
+	if t.loaded {
+		return nil
+	}
+
+	if t.last, err = t.storage.GetLastUpdateID(ctx); err != nil {
+		return err
+	}
+
+	t.loaded = true
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package telegram
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	ctx := context.Background()
+	key := StateKey{ChatID: 42, UserID: 24}
+	s := NewMemoryStorage()
+
+	state, err := s.GetState(ctx, key)
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+
+	assert.NoError(t, s.SetState(ctx, key, &State{Name: "name", Data: map[string]string{"a": "b"}}))
+
+	state, err = s.GetState(ctx, key)
+	assert.NoError(t, err)
+	assert.Equal(t, &State{Name: "name", Data: map[string]string{"a": "b"}}, state)
+
+	state.Data["a"] = "c"
+	state, err = s.GetState(ctx, key)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", state.Data["a"], "stored state must not be changed outside")
+
+	assert.NoError(t, s.DeleteState(ctx, key))
+
+	state, err = s.GetState(ctx, key)
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+
+	assert.NoError(t, s.SetLastUpdateID(ctx, 42))
+
+	last, err := s.GetLastUpdateID(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), last)
+}
+
+func TestFileStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegram")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	path := filepath.Join(dir, "states.json")
+	expires := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s, err := NewFileStorage(path)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	assert.NoError(t, s.SetState(ctx, StateKey{ChatID: 1, UserID: 1}, &State{Name: "first"}))
+	assert.NoError(t, s.SetState(ctx, StateKey{ChatID: 2, UserID: 2}, &State{
+		Name:    "second",
+		Data:    map[string]string{"a": "b"},
+		Expires: expires,
+	}))
+	assert.NoError(t, s.DeleteState(ctx, StateKey{ChatID: 1, UserID: 1}))
+	assert.NoError(t, s.SetLastUpdateID(ctx, 42))
+
+	t.Run("reload", func(t *testing.T) {
+		s, err := NewFileStorage(path)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		state, err := s.GetState(ctx, StateKey{ChatID: 1, UserID: 1})
+		assert.NoError(t, err)
+		assert.Nil(t, state)
+
+		state, err = s.GetState(ctx, StateKey{ChatID: 2, UserID: 2})
+		assert.NoError(t, err)
+		if assert.NotNil(t, state) {
+			assert.Equal(t, "second", state.Name)
+			assert.Equal(t, map[string]string{"a": "b"}, state.Data)
+			assert.True(t, expires.Equal(state.Expires))
+		}
+
+		last, err := s.GetLastUpdateID(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), last)
+	})
+	t.Run("concurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+
+		for i := int64(1); i <= 20; i++ {
+			wg.Add(1)
+
+			go func(i int64) {
+				defer wg.Done()
+
+				assert.NoError(t, s.SetState(ctx, StateKey{ChatID: i, UserID: i}, &State{Name: "concurrent"}))
+			}(i)
+		}
+
+		wg.Wait()
+
+		reloaded, err := NewFileStorage(path)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		for i := int64(1); i <= 20; i++ {
+			state, err := reloaded.GetState(ctx, StateKey{ChatID: i, UserID: i})
+			assert.NoError(t, err)
+			assert.NotNil(t, state, i)
+		}
+	})
+	t.Run("cleanup", func(t *testing.T) {
+		expired, active := StateKey{ChatID: 100, UserID: 100}, StateKey{ChatID: 101, UserID: 101}
+
+		assert.NoError(t, s.SetState(ctx, expired, &State{Name: "expired", Expires: expires}))
+		assert.NoError(t, s.SetState(ctx, active, &State{Name: "active", Expires: expires.Add(time.Hour)}))
+		assert.NoError(t, s.DeleteExpiredStates(ctx, expires))
+
+		reloaded, err := NewFileStorage(path)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		state, err := reloaded.GetState(ctx, expired)
+		assert.NoError(t, err)
+		assert.Nil(t, state)
+
+		state, err = reloaded.GetState(ctx, active)
+		assert.NoError(t, err)
+		assert.NotNil(t, state)
+
+		state, err = reloaded.GetState(ctx, StateKey{ChatID: 1, UserID: 1})
+		assert.NoError(t, err)
+		assert.NotNil(t, state, "state without expiration time must be kept")
+	})
+	t.Run("invalid", func(t *testing.T) {
+		invalid := filepath.Join(dir, "invalid.json")
+		assert.NoError(t, ioutil.WriteFile(invalid, []byte("{"), 0600))
+
+		_, err := NewFileStorage(invalid)
+		assert.Error(t, err)
+	})
+}
+
+func TestOffsetTracker(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStorage()
+	assert.NoError(t, s.SetLastUpdateID(ctx, 10000000))
+
+	tracker := &offsetTracker{storage: s}
+	begin := func(id int64) bool {
+		ok, err := tracker.begin(ctx, id)
+		assert.NoError(t, err)
+
+		return ok
+	}
+	last := func() int64 {
+		id, err := s.GetLastUpdateID(ctx)
+		assert.NoError(t, err)
+
+		return id
+	}
+
+	assert.False(t, begin(10000000), "processed before restart")
+	assert.True(t, begin(10000001))
+	assert.False(t, begin(10000001), "redelivered while handling")
+
+	assert.True(t, begin(10000003))
+	assert.NoError(t, tracker.done(ctx, 10000003))
+	assert.Equal(t, int64(10000000), last(), "previous update is still handling")
+	assert.False(t, begin(10000003), "redelivered after handling")
+
+	assert.True(t, begin(10000002), "delivered out of order")
+	assert.NoError(t, tracker.done(ctx, 10000001))
+	assert.Equal(t, int64(10000001), last())
+	assert.NoError(t, tracker.done(ctx, 10000002))
+	assert.Equal(t, int64(10000003), last())
+
+	assert.True(t, begin(10000004))
+	tracker.abort(10000004)
+	assert.True(t, begin(10000004), "redelivered after rejection")
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		assert.NoError(t, tracker.done(ctx, 10000004))
+	}()
+
+	assert.NoError(t, tracker.wait(ctx, 10000004))
+	assert.Equal(t, int64(10000004), last())
+
+	assert.True(t, begin(10000005))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.Error(t, tracker.wait(canceled, 10000005))
+
+	assert.True(t, begin(5), "new sequence after reset")
+	assert.NoError(t, tracker.done(ctx, 5))
+	assert.Equal(t, int64(5), last())
+	assert.False(t, begin(5))
+}
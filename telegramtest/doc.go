@@ -0,0 +1,2 @@
+// Package telegramtest contains an in-process fake Bot API server for testing bots offline.
+package telegramtest // import "gitlab.com/toby3d/telegram/v5/telegramtest"
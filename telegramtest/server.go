@@ -0,0 +1,932 @@
+package telegramtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	"gitlab.com/toby3d/telegram/v5"
+)
+
+type (
+	// Server is an in-process fake Bot API server which serves methods from the in-memory state. Use Endpoint
+	// (or NewBot) for connecting a real telegram.Bot to it.
+	Server struct {
+		// Bot user returned by getMe method
+		Me telegram.User
+
+		// Access token of the bot, requests with any other token are unauthorized
+		Token string
+
+		// Base URL of the server
+		URL string
+
+		srv           *httptest.Server
+		mu            sync.Mutex
+		notify        chan struct{}
+		updates       []*telegram.Update
+		lastUpdateID  int64
+		chats         map[int64]*telegram.Chat
+		messages      []*telegram.Message
+		lastMessageID map[int64]int64
+		answers       []telegram.AnswerCallbackQuery
+		files         map[string]*file
+		errors        map[string][]telegram.Error
+		webhook       telegram.WebhookInfo
+		secretToken   string
+		allowed       []string
+		lastFileID    int
+	}
+
+	file struct {
+		telegram.File
+		data []byte
+	}
+
+	// params represents decoded parameters of the method request.
+	params map[string]json.RawMessage
+)
+
+var errUnknownChat = errors.New("chat_id is empty")
+
+// NewServer starts a new fake Bot API server for the bot with the provided access token. The caller must call
+// Close when finished, to shut it down.
+func NewServer(token string) *Server {
+	s := &Server{
+		Me:            telegram.User{ID: botID(token), IsBot: true, FirstName: "Test", Username: "TestBot"},
+		Token:         token,
+		notify:        make(chan struct{}),
+		chats:         make(map[int64]*telegram.Chat),
+		lastMessageID: make(map[int64]int64),
+		files:         make(map[string]*file),
+		errors:        make(map[string][]telegram.Error),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.ServeHTTP))
+	s.URL = s.srv.URL
+
+	return s
+}
+
+// Close shuts down the server and blocks until all outstanding requests on this server have completed.
+func (s *Server) Close() { s.srv.Close() }
+
+// Endpoint returns the telegram.Endpoint of the server. Requests into the test environment (with Test field of the
+// endpoint) are served by the same state.
+func (s *Server) Endpoint() telegram.Endpoint { return telegram.Endpoint{URL: s.URL} }
+
+// NewBot creates a new telegram.Bot connected to the server.
+func (s *Server) NewBot() (*telegram.Bot, error) {
+	return telegram.NewWithEndpoint(s.Token, s.Endpoint())
+}
+
+// AddChat registers the chat in the server state, so methods return it instead of a generated one.
+func (s *Server) AddChat(c telegram.Chat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chats[c.ID] = &c
+}
+
+// AddUpdate injects the update for the bot. Update identifier is assigned automatically if it's empty. The update
+// is delivered by webhook immediately if it's set, otherwise it's returned by the next getUpdates call. Method call
+// in the webhook response is performed as a regular request of the bot. Update is dropped if its kind is not in
+// allowed_updates of the webhook or the last getUpdates call (chat_member updates are not allowed by default).
+func (s *Server) AddUpdate(u *telegram.Update) error {
+	s.mu.Lock()
+
+	if u.ID == 0 {
+		u.ID = s.lastUpdateID + 1
+	}
+
+	if u.ID > s.lastUpdateID {
+		s.lastUpdateID = u.ID
+	}
+
+	webhook, secretToken := s.webhook.URL, s.secretToken
+
+	allowed := s.allowed
+	if webhook != "" {
+		allowed = s.webhook.AllowedUpdates
+	}
+
+	if !isAllowedUpdate(u, allowed) {
+		s.mu.Unlock()
+
+		return nil
+	}
+
+	if webhook == "" {
+		s.updates = append(s.updates, u)
+		close(s.notify)
+		s.notify = make(chan struct{})
+	}
+
+	s.mu.Unlock()
+
+	if webhook == "" {
+		return nil
+	}
+
+	src, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, webhook, bytes.NewReader(src))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if secretToken != "" {
+		req.Header.Set(telegram.HeaderSecretToken, secretToken)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	// NOTE(toby3d): webhook can reply to the update by the method call in the response body.
+	reply := make(params)
+	if err = json.NewDecoder(resp.Body).Decode(&reply); err != nil || !reply.has("method") {
+		return nil
+	}
+
+	_, err = s.call(context.Background(), reply.string("method"), reply)
+
+	return err
+}
+
+// AddMessage injects a new text message from the user into the chat as update and returns it.
+func (s *Server) AddMessage(chatID int64, from *telegram.User, text string) (*telegram.Message, error) {
+	var entities []*telegram.MessageEntity
+
+	if strings.HasPrefix(text, "/") {
+		length := strings.IndexAny(text, " \n")
+		if length < 0 {
+			length = len(text)
+		}
+
+		entities = append(entities, &telegram.MessageEntity{
+			Type:   telegram.EntityBotCommand,
+			Length: len(utf16.Encode([]rune(text[:length]))),
+		})
+	}
+
+	s.mu.Lock()
+	m := s.newMessage(chatID)
+	m.From = from
+	m.Text = text
+	m.Entities = entities
+	s.messages = append(s.messages, m)
+	result := *m
+	s.mu.Unlock()
+
+	return &result, s.AddUpdate(&telegram.Update{Message: &result})
+}
+
+// AddError makes the next call of the method failed with err. Errors of the same method are returned in the order
+// of adding.
+func (s *Server) AddError(method string, err telegram.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.errors[method] = append(s.errors[method], err)
+}
+
+// AddFile stores the file contents with the path and returns the File which can be requested by getFile method
+// and downloaded.
+func (s *Server) AddFile(filePath string, data []byte) *telegram.File {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastFileID++
+
+	f := &file{data: data, File: telegram.File{
+		FileID:       "file_" + strconv.Itoa(s.lastFileID),
+		FileUniqueID: "unique_" + strconv.Itoa(s.lastFileID),
+		FileSize:     len(data),
+		FilePath:     filePath,
+	}}
+	s.files[f.FileID] = f
+
+	result := f.File
+
+	return &result
+}
+
+// Messages returns all messages sent by the bot into the chat with chatID (or into all chats, if chatID is zero)
+// in the current state, with all edits.
+func (s *Server) Messages(chatID int64) []*telegram.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]*telegram.Message, 0)
+
+	for _, m := range s.messages {
+		if (chatID != 0 && m.Chat.ID != chatID) || m.From == nil || m.From.ID != s.Me.ID {
+			continue
+		}
+
+		msg := *m
+		result = append(result, &msg)
+	}
+
+	return result
+}
+
+// CallbackAnswers returns all answers of the bot to callback queries.
+func (s *Server) CallbackAnswers() []telegram.AnswerCallbackQuery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]telegram.AnswerCallbackQuery(nil), s.answers...)
+}
+
+// WebhookInfo returns current webhook status. PendingUpdateCount contains the number of not yet confirmed updates.
+func (s *Server) WebhookInfo() telegram.WebhookInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info := s.webhook
+	info.PendingUpdateCount = len(s.updates)
+
+	return info
+}
+
+// ServeHTTP implements http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	// NOTE(toby3d): methods of the test environment are requested by /bot<token>/test/<method> path.
+	if len(parts) == 3 && parts[1] == "test" {
+		parts = []string{parts[0], parts[2]}
+	}
+
+	switch {
+	case len(parts) >= 3 && parts[0] == "file" && parts[1] == "bot"+s.Token:
+		s.serveFile(w, parts[2:]...)
+	case len(parts) == 2 && parts[0] == "bot"+s.Token:
+		s.serveMethod(w, r, parts[1])
+	case len(parts) == 2 && strings.HasPrefix(parts[0], "bot"):
+		writeError(w, telegram.Error{Code: http.StatusUnauthorized, Description: "Unauthorized"})
+	default:
+		writeError(w, telegram.Error{Code: http.StatusNotFound, Description: "Not Found"})
+	}
+}
+
+func (s *Server) serveFile(w http.ResponseWriter, parts ...string) {
+	filePath, testPath := path.Join(parts...), ""
+	if len(parts) > 1 && parts[0] == "test" {
+		testPath = path.Join(parts[1:]...) // NOTE(toby3d): file of the test environment
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, f := range s.files {
+		if f.FilePath != filePath && f.FilePath != testPath {
+			continue
+		}
+
+		_, _ = w.Write(f.data)
+
+		return
+	}
+
+	writeError(w, telegram.Error{Code: http.StatusNotFound, Description: "Not Found"})
+}
+
+func (s *Server) serveMethod(w http.ResponseWriter, r *http.Request, method string) {
+	s.mu.Lock()
+	if errs := s.errors[method]; len(errs) > 0 {
+		s.errors[method] = errs[1:]
+		s.mu.Unlock()
+		writeError(w, errs[0])
+
+		return
+	}
+	s.mu.Unlock()
+
+	p, err := parseParams(r)
+	if err != nil {
+		writeError(w, badRequest(err.Error()))
+
+		return
+	}
+
+	result, err := s.call(r.Context(), method, p)
+
+	var tgErr telegram.Error
+	if errors.As(err, &tgErr) {
+		writeError(w, tgErr)
+
+		return
+	}
+
+	if err != nil {
+		writeError(w, badRequest(err.Error()))
+
+		return
+	}
+
+	src, err := json.Marshal(result)
+	if err != nil {
+		writeError(w, telegram.Error{Code: http.StatusInternalServerError, Description: err.Error()})
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, telegram.Response{Ok: true, Result: src})
+}
+
+// call performs the method with params p and returns its result.
+func (s *Server) call(ctx context.Context, method string, p params) (interface{}, error) {
+	switch method {
+	case telegram.MethodGetMe:
+		return s.Me, nil
+	case telegram.MethodLogOut, telegram.MethodClose, telegram.MethodSendChatAction:
+		return true, nil
+	case telegram.MethodGetUpdates:
+		return s.getUpdates(ctx, p)
+	case telegram.MethodSetWebhook:
+		return s.setWebhook(p)
+	case telegram.MethodDeleteWebhook:
+		return s.setWebhook(params{})
+	case telegram.MethodGetWebhookInfo:
+		return s.WebhookInfo(), nil
+	case telegram.MethodSendMessage:
+		return s.sendMessage(p)
+	case telegram.MethodSendPhoto, telegram.MethodSendAudio, telegram.MethodSendDocument,
+		telegram.MethodSendVideo, telegram.MethodSendAnimation, telegram.MethodSendVoice,
+		telegram.MethodSendVideoNote, telegram.MethodSendSticker:
+		return s.sendMedia(p)
+	case telegram.MethodForwardMessage, telegram.MethodCopyMessage:
+		return s.copyMessage(p, method == telegram.MethodCopyMessage)
+	case telegram.MethodEditMessageText, telegram.MethodEditMessageCaption, telegram.MethodEditMessageReplyMarkup:
+		return s.editMessage(p, method)
+	case telegram.MethodDeleteMessage:
+		return s.deleteMessage(p)
+	case telegram.MethodAnswerCallbackQuery:
+		return s.answerCallbackQuery(p)
+	case telegram.MethodGetFile:
+		return s.getFile(p)
+	case telegram.MethodGetChat:
+		return s.getChat(p)
+	default:
+		return nil, telegram.Error{Code: http.StatusNotFound, Description: "Not Found: method not found"}
+	}
+}
+
+func (s *Server) getUpdates(ctx context.Context, p params) ([]*telegram.Update, error) {
+	offset, limit, timeout := p.int64("offset"), int(p.int64("limit")), time.Duration(p.int64("timeout"))
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+
+	if p.has("allowed_updates") {
+		var allowed []string
+		if err := p.decode("allowed_updates", &allowed); err != nil {
+			return nil, err
+		}
+
+		// NOTE(toby3d): like the Bot API, the list is used by the next calls without this parameter.
+		s.mu.Lock()
+		s.allowed = allowed
+		s.mu.Unlock()
+	}
+
+	deadline := time.NewTimer(timeout * time.Second)
+	defer deadline.Stop()
+
+	for {
+		s.mu.Lock()
+
+		if s.webhook.URL != "" {
+			s.mu.Unlock()
+
+			return nil, telegram.Error{
+				Code:        http.StatusConflict,
+				Description: "Conflict: can't use getUpdates method while webhook is active",
+			}
+		}
+
+		// NOTE(toby3d): updates are confirmed by call with the offset higher than their identifiers.
+		for len(s.updates) > 0 && s.updates[0].ID < offset {
+			s.updates = s.updates[1:]
+		}
+
+		pending := s.updates[:0]
+		for _, u := range s.updates {
+			if isAllowedUpdate(u, s.allowed) {
+				pending = append(pending, u)
+			}
+		}
+
+		s.updates = pending
+
+		result := make([]*telegram.Update, 0, limit)
+		for i := 0; i < len(s.updates) && len(result) < limit; i++ {
+			result = append(result, s.updates[i])
+		}
+
+		notify := s.notify
+		s.mu.Unlock()
+
+		if len(result) > 0 || timeout <= 0 {
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, nil
+		case <-deadline.C:
+			return result, nil
+		case <-notify:
+		}
+	}
+}
+
+func (s *Server) setWebhook(p params) (bool, error) {
+	var allowedUpdates []string
+	if err := p.decode("allowed_updates", &allowedUpdates); err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.webhook = telegram.WebhookInfo{
+		URL:            p.string("url"),
+		MaxConnections: int(p.int64("max_connections")),
+		AllowedUpdates: allowedUpdates,
+	}
+	s.secretToken = p.string("secret_token")
+
+	if p.bool("drop_pending_updates") {
+		s.updates = nil
+	}
+
+	return true, nil
+}
+
+func (s *Server) sendMessage(p params) (*telegram.Message, error) {
+	text := p.string("text")
+	if text == "" {
+		return nil, badRequest("message text is empty")
+	}
+
+	m, err := s.newBotMessage(p)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m.Text = text
+	s.messages = append(s.messages, m)
+	result := *m
+
+	return &result, nil
+}
+
+func (s *Server) sendMedia(p params) (*telegram.Message, error) {
+	m, err := s.newBotMessage(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = p.decode("caption_entities", &m.CaptionEntities); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastFileID++
+
+	f := telegram.File{FileID: "file_" + strconv.Itoa(s.lastFileID), FileUniqueID: "unique_" +
+		strconv.Itoa(s.lastFileID)}
+
+	switch {
+	case p.has("photo"):
+		m.Photo = []*telegram.PhotoSize{{FileID: f.FileID, FileUniqueID: f.FileUniqueID}}
+	case p.has("audio"):
+		m.Audio = &telegram.Audio{FileID: f.FileID, FileUniqueID: f.FileUniqueID}
+	case p.has("video"):
+		m.Video = &telegram.Video{FileID: f.FileID, FileUniqueID: f.FileUniqueID}
+	case p.has("animation"):
+		m.Animation = &telegram.Animation{FileID: f.FileID, FileUniqueID: f.FileUniqueID}
+	case p.has("voice"):
+		m.Voice = &telegram.Voice{FileID: f.FileID, FileUniqueID: f.FileUniqueID}
+	case p.has("video_note"):
+		m.VideoNote = &telegram.VideoNote{FileID: f.FileID, FileUniqueID: f.FileUniqueID}
+	case p.has("sticker"):
+		m.Sticker = &telegram.Sticker{FileID: f.FileID, FileUniqueID: f.FileUniqueID}
+	default:
+		m.Document = &telegram.Document{FileID: f.FileID, FileUniqueID: f.FileUniqueID}
+	}
+
+	m.Caption = p.string("caption")
+	s.messages = append(s.messages, m)
+	result := *m
+
+	return &result, nil
+}
+
+func (s *Server) copyMessage(p params, isCopy bool) (interface{}, error) {
+	s.mu.Lock()
+	src := s.findMessage(p.int64("from_chat_id"), p.int64("message_id"))
+	s.mu.Unlock()
+
+	if src == nil {
+		return nil, badRequest("message to copy not found")
+	}
+
+	m, err := s.newBotMessage(p)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, chat, date := m.ID, m.Chat, m.Date
+	*m = *src
+	m.ID, m.Chat, m.Date = id, chat, date
+	s.messages = append(s.messages, m)
+
+	if isCopy {
+		return telegram.MessageID{MessageID: m.ID}, nil
+	}
+
+	m.ForwardFrom = src.From
+	m.ForwardDate = src.Date
+	result := *m
+
+	return &result, nil
+}
+
+func (s *Server) editMessage(p params, method string) (interface{}, error) {
+	if p.has("inline_message_id") {
+		return true, nil
+	}
+
+	var markup *telegram.InlineKeyboardMarkup
+	if err := p.decode("reply_markup", &markup); err != nil {
+		return nil, err
+	}
+
+	var entities []*telegram.MessageEntity
+	if err := p.decode("entities", &entities); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := s.findMessage(p.int64("chat_id"), p.int64("message_id"))
+	if m == nil {
+		return nil, badRequest("message to edit not found")
+	}
+
+	edited := *m
+
+	switch method {
+	case telegram.MethodEditMessageText:
+		edited.Text, edited.Entities = p.string("text"), entities
+	case telegram.MethodEditMessageCaption:
+		edited.Caption = p.string("caption")
+	}
+
+	edited.ReplyMarkup = markup
+
+	if edited.Text == m.Text && edited.Caption == m.Caption && equalJSON(edited.ReplyMarkup, m.ReplyMarkup) &&
+		equalJSON(edited.Entities, m.Entities) {
+		return nil, badRequest("message is not modified: specified new message content and reply markup are " +
+			"exactly the same as a current content and reply markup of the message")
+	}
+
+	edited.EditDate = time.Now().Unix()
+	*m = edited
+	result := *m
+
+	return &result, nil
+}
+
+func (s *Server) deleteMessage(p params) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chatID, messageID := p.int64("chat_id"), p.int64("message_id")
+
+	for i := range s.messages {
+		if s.messages[i].Chat.ID == chatID && s.messages[i].ID == messageID {
+			s.messages = append(s.messages[:i], s.messages[i+1:]...)
+
+			return true, nil
+		}
+	}
+
+	return false, badRequest("message to delete not found")
+}
+
+func (s *Server) answerCallbackQuery(p params) (bool, error) {
+	answer := telegram.AnswerCallbackQuery{
+		CallbackQueryID: p.string("callback_query_id"),
+		Text:            p.string("text"),
+		URL:             p.string("url"),
+		ShowAlert:       p.bool("show_alert"),
+		CacheTime:       int(p.int64("cache_time")),
+	}
+
+	if answer.CallbackQueryID == "" {
+		return false, badRequest("query is too old and response timeout expired or query ID is invalid")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// NOTE(toby3d): callback query can be answered only once.
+	for i := range s.answers {
+		if s.answers[i].CallbackQueryID == answer.CallbackQueryID {
+			return false, badRequest("query is too old and response timeout expired or query ID is invalid")
+		}
+	}
+
+	s.answers = append(s.answers, answer)
+
+	return true, nil
+}
+
+func (s *Server) getFile(p params) (*telegram.File, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.files[p.string("file_id")]
+	if !ok {
+		return nil, badRequest("invalid file_id")
+	}
+
+	result := f.File
+
+	return &result, nil
+}
+
+func (s *Server) getChat(p params) (*telegram.Chat, error) {
+	chatID := p.int64("chat_id")
+	if chatID == 0 {
+		return nil, errUnknownChat
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := *s.chat(chatID)
+
+	return &result, nil
+}
+
+// newBotMessage creates a new message from the bot into the chat from chat_id parameter.
+func (s *Server) newBotMessage(p params) (*telegram.Message, error) {
+	chatID := p.int64("chat_id")
+	if chatID == 0 {
+		return nil, errUnknownChat
+	}
+
+	var markup *telegram.InlineKeyboardMarkup
+	if err := p.decode("reply_markup", &markup); err != nil {
+		return nil, err
+	}
+
+	var entities []*telegram.MessageEntity
+	if err := p.decode("entities", &entities); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	me := s.Me
+	m := s.newMessage(chatID)
+	m.From = &me
+	m.Entities = entities
+	m.ReplyMarkup = markup
+
+	// NOTE(toby3d): stored messages are changed by edits, so results must not share them.
+	if replyTo := s.findMessage(chatID, p.int64("reply_to_message_id")); replyTo != nil {
+		result := *replyTo
+		m.ReplyToMessage = &result
+	}
+
+	return m, nil
+}
+
+// newMessage creates an empty message with a next identifier into the chat with chatID. The caller must hold mu.
+func (s *Server) newMessage(chatID int64) *telegram.Message {
+	s.lastMessageID[chatID]++
+
+	return &telegram.Message{
+		ID:   s.lastMessageID[chatID],
+		Date: time.Now().Unix(),
+		Chat: s.chat(chatID),
+	}
+}
+
+// chat returns the known chat or creates a new one by the sign of chatID. The caller must hold mu.
+func (s *Server) chat(chatID int64) *telegram.Chat {
+	if c, ok := s.chats[chatID]; ok {
+		return c
+	}
+
+	c := &telegram.Chat{ID: chatID, Type: telegram.ChatPrivate}
+	if chatID < 0 {
+		c.Type = telegram.ChatSuperGroup
+	}
+
+	s.chats[chatID] = c
+
+	return c
+}
+
+// findMessage returns the sent message by chat and message identifiers. The caller must hold mu.
+func (s *Server) findMessage(chatID, messageID int64) *telegram.Message {
+	for _, m := range s.messages {
+		if m.Chat.ID == chatID && m.ID == messageID {
+			return m
+		}
+	}
+
+	return nil
+}
+
+// parseParams decodes parameters of the method from JSON or form request body.
+func parseParams(r *http.Request) (params, error) {
+	p := make(params)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	switch mediaType {
+	case "multipart/form-data", "application/x-www-form-urlencoded":
+		if err := r.ParseMultipartForm(32 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return nil, err
+		}
+
+		for key := range r.Form {
+			p[key] = formValue(r.Form.Get(key))
+		}
+
+		if r.MultipartForm != nil {
+			for key := range r.MultipartForm.File {
+				if _, ok := p[key]; !ok {
+					p[key] = formValue("attach://" + key)
+				}
+			}
+		}
+	default:
+		src := new(bytes.Buffer)
+		if _, err := src.ReadFrom(r.Body); err != nil {
+			return nil, err
+		}
+
+		if len(bytes.TrimSpace(src.Bytes())) == 0 {
+			return p, nil
+		}
+
+		if err := json.Unmarshal(src.Bytes(), &p); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// formValue converts the form field value into JSON: objects, arrays, numbers and booleans are kept as is, any
+// other values are quoted as strings.
+func formValue(val string) json.RawMessage {
+	if json.Valid([]byte(val)) {
+		return json.RawMessage(val)
+	}
+
+	src, _ := json.Marshal(val)
+
+	return src
+}
+
+// isAllowedUpdate checks that kind of u is in the allowed list. Empty list allows updates of all kinds except
+// chat_member, like the Bot API does.
+func isAllowedUpdate(u *telegram.Update, allowed []string) bool {
+	kind := u.Kind()
+	if kind == telegram.KindUnknown {
+		return true
+	}
+
+	if len(allowed) == 0 {
+		return kind != telegram.KindChatMember
+	}
+
+	for _, name := range allowed {
+		if name == kind.String() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p params) has(key string) bool {
+	val, ok := p[key]
+
+	return ok && string(val) != "null" && string(val) != `""`
+}
+
+func (p params) string(key string) string {
+	var result string
+	if err := json.Unmarshal(p[key], &result); err != nil {
+		return string(p[key])
+	}
+
+	return result
+}
+
+func (p params) int64(key string) int64 {
+	result, _ := strconv.ParseInt(strings.Trim(string(p[key]), `"`), 10, 64)
+
+	return result
+}
+
+func (p params) bool(key string) bool {
+	result, _ := strconv.ParseBool(strings.Trim(string(p[key]), `"`))
+
+	return result
+}
+
+func (p params) decode(key string, dst interface{}) error {
+	if !p.has(key) {
+		return nil
+	}
+
+	src := p[key]
+
+	// NOTE(toby3d): form fields contains JSON-serialized objects as strings.
+	var str string
+	if err := json.Unmarshal(src, &str); err == nil {
+		src = []byte(str)
+	}
+
+	if err := json.Unmarshal(src, dst); err != nil {
+		return fmt.Errorf("can't parse %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func badRequest(description string) telegram.Error {
+	return telegram.Error{Code: http.StatusBadRequest, Description: "Bad Request: " + description}
+}
+
+func writeError(w http.ResponseWriter, err telegram.Error) {
+	writeJSON(w, err.Code, telegram.Response{
+		ErrorCode:   err.Code,
+		Description: err.Description,
+		Parameters:  err.Parameters,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, resp telegram.Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func equalJSON(a, b interface{}) bool {
+	srcA, _ := json.Marshal(a)
+	srcB, _ := json.Marshal(b)
+
+	return bytes.Equal(srcA, srcB)
+}
+
+// botID returns bot identifier from the first part of the access token.
+func botID(token string) int64 {
+	id, _ := strconv.ParseInt(strings.SplitN(token, ":", 2)[0], 10, 64)
+
+	return id
+}
@@ -0,0 +1,202 @@
+package telegramtest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/toby3d/telegram/v5"
+	"gitlab.com/toby3d/telegram/v5/telegramtest"
+)
+
+const token string = "123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11"
+
+func TestServerGetMe(t *testing.T) {
+	srv := telegramtest.NewServer(token)
+	defer srv.Close()
+
+	bot, err := srv.NewBot()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(123456), bot.ID)
+	assert.True(t, bot.IsBot)
+
+	srv.Token = "42:abc"
+	_, err = bot.GetMe()
+	assert.Error(t, err)
+}
+
+func TestServerSendMessage(t *testing.T) {
+	srv := telegramtest.NewServer(token)
+	defer srv.Close()
+
+	bot, err := srv.NewBot()
+	assert.NoError(t, err)
+
+	msg, err := bot.SendMessage(telegram.NewMessage(telegram.ChatID{ID: 42}, "hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", msg.Text)
+	assert.True(t, bot.IsMessageFromMe(*msg))
+
+	t.Run("edit", func(t *testing.T) {
+		p := telegram.NewEditText("world")
+		p.ChatID, p.MessageID = telegram.ChatID{ID: 42}, msg.ID
+
+		edited, err := bot.EditMessageText(p)
+		assert.NoError(t, err)
+		assert.Equal(t, "world", edited.Text)
+		assert.True(t, edited.HasBeenEdited())
+
+		_, err = bot.EditMessageText(p)
+		assert.True(t, errors.Is(err, telegram.ErrNotModified))
+
+		p.MessageID = 100500
+		_, err = bot.EditMessageText(p)
+		assert.True(t, errors.Is(err, telegram.ErrMessageToEditNotFound))
+	})
+
+	messages := srv.Messages(42)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "world", messages[0].Text)
+	assert.Empty(t, srv.Messages(24))
+}
+
+func TestServerAddError(t *testing.T) {
+	srv := telegramtest.NewServer(token)
+	defer srv.Close()
+
+	bot, err := srv.NewBot()
+	assert.NoError(t, err)
+
+	srv.AddError(telegram.MethodSendMessage, telegram.Error{
+		Code:        http.StatusForbidden,
+		Description: "Forbidden: bot was blocked by the user",
+	})
+
+	_, err = bot.SendMessage(telegram.NewMessage(telegram.ChatID{ID: 42}, "hello"))
+	assert.True(t, errors.Is(err, telegram.ErrBlocked))
+
+	_, err = bot.SendMessage(telegram.NewMessage(telegram.ChatID{ID: 42}, "hello"))
+	assert.NoError(t, err)
+
+	t.Run("flood", func(t *testing.T) {
+		srv.AddError(telegram.MethodSendMessage, telegram.Error{
+			Code:        http.StatusTooManyRequests,
+			Description: "Too Many Requests: retry after 1",
+			Parameters:  &telegram.ResponseParameters{RetryAfter: 1},
+		})
+
+		bot.SetLimiter(nil)
+
+		_, err = bot.SendMessage(telegram.NewMessage(telegram.ChatID{ID: 42}, "hello"))
+
+		var tgErr *telegram.Error
+		assert.True(t, errors.As(err, &tgErr))
+		assert.Equal(t, time.Second, tgErr.RetryAfter())
+	})
+}
+
+func TestServerGetUpdates(t *testing.T) {
+	srv := telegramtest.NewServer(token)
+	defer srv.Close()
+
+	bot, err := srv.NewBot()
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	updates := bot.NewLongPollingChannelContext(ctx, &telegram.GetUpdates{Limit: 10, Timeout: 1})
+
+	_, err = srv.AddMessage(42, &telegram.User{ID: 42, FirstName: "Maxim"}, "/start")
+	assert.NoError(t, err)
+
+	update := <-updates
+	assert.True(t, update.IsMessage())
+	assert.True(t, update.Message.IsCommandEqual(telegram.CommandStart))
+
+	cancel()
+
+	for range updates {
+	}
+
+	info, err := bot.GetWebhookInfo()
+	assert.NoError(t, err)
+	assert.Empty(t, info.URL)
+}
+
+func TestServerGetUpdatesAllowedUpdates(t *testing.T) {
+	srv := telegramtest.NewServer(token)
+	defer srv.Close()
+
+	bot, err := srv.NewBot()
+	assert.NoError(t, err)
+
+	r := telegram.NewRouter()
+	r.OnCallbackQuery(func(ctx context.Context, q *telegram.CallbackQuery) error { return nil })
+
+	user := &telegram.User{ID: 42, FirstName: "Maxim"}
+
+	_, err = srv.AddMessage(42, user, "hello")
+	assert.NoError(t, err)
+	assert.NoError(t, srv.AddUpdate(&telegram.Update{CallbackQuery: &telegram.CallbackQuery{ID: "1", From: user}}))
+
+	result, err := bot.GetUpdates(&telegram.GetUpdates{AllowedUpdates: r.AllowedUpdates()})
+	assert.NoError(t, err)
+
+	if assert.Len(t, result, 1) {
+		assert.True(t, result[0].IsCallbackQuery())
+	}
+
+	_, err = srv.AddMessage(42, user, "ignored")
+	assert.NoError(t, err)
+	assert.NoError(t, srv.AddUpdate(&telegram.Update{CallbackQuery: &telegram.CallbackQuery{ID: "2", From: user}}))
+
+	result, err = bot.GetUpdates(&telegram.GetUpdates{Offset: result[0].ID + 1})
+	assert.NoError(t, err)
+
+	if assert.Len(t, result, 1, "allowed updates must be kept from the previous call") {
+		assert.Equal(t, "2", result[0].CallbackQuery.ID)
+	}
+}
+
+func TestServerTestEnvironment(t *testing.T) {
+	srv := telegramtest.NewServer(token)
+	defer srv.Close()
+
+	e := srv.Endpoint()
+	e.Test = true
+
+	bot, err := telegram.NewWithEndpoint(token, e)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(123456), bot.ID)
+
+	f := srv.AddFile("documents/file_0.txt", []byte("hello"))
+
+	data, err := bot.DownloadFile(context.Background(), f.FilePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+}
+
+func TestServerGetFile(t *testing.T) {
+	srv := telegramtest.NewServer(token)
+	defer srv.Close()
+
+	bot, err := srv.NewBot()
+	assert.NoError(t, err)
+
+	f := srv.AddFile("documents/file_0.txt", []byte("hello"))
+
+	result, err := bot.GetFile(f.FileID)
+	assert.NoError(t, err)
+	assert.Equal(t, f, result)
+
+	data, err := bot.DownloadFile(context.Background(), result.FilePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	_, err = bot.DownloadFile(context.Background(), "documents/file_1.txt")
+	assert.Error(t, err)
+}
@@ -0,0 +1,166 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	nethttp "net/http"
+
+	http "github.com/valyala/fasthttp"
+)
+
+type (
+	// Transport performs HTTP requests to the Bot API server.
+	Transport interface {
+		// Do sends req until it's done or ctx is canceled and returns response status code with body.
+		Do(ctx context.Context, req *TransportRequest) (status int, body []byte, err error)
+	}
+
+	// TransportRequest represents a single HTTP request to the Bot API server.
+	TransportRequest struct {
+		// HTTP method, POST for API methods and GET for files downloads
+		Method string
+
+		// Full URL of the request
+		URL string
+
+		// Content-Type header of the body
+		ContentType string
+
+		// Request body, it can be nil. Multipart bodies are streamed and have unknown length.
+		Body io.Reader
+	}
+
+	// FastHTTPTransport is a Transport implementation on the fasthttp.Client.
+	//
+	// The fasthttp.Client can not abort requests, so canceled context only abandons the request: Do returns
+	// immediately, but the request keeps running in the background until the server responds or the deadline of
+	// ctx (if any) is passed. For example, the abandoned getUpdates request lives up to its long polling timeout
+	// and holds the connection all this time. Use NetHTTPTransport (the default one) if requests must be aborted on
+	// cancel.
+	FastHTTPTransport struct {
+		Client *http.Client
+	}
+
+	// NetHTTPTransport is a Transport implementation on the net/http.Client. It's used by default, because the
+	// request and its connection are closed as soon as ctx is canceled.
+	NetHTTPTransport struct {
+		Client *nethttp.Client
+	}
+)
+
+const userAgent string = "toby3d/telegram"
+
+// NewFastHTTPTransport creates a new Transport based on the fasthttp.Client. Default client is used if c is nil.
+func NewFastHTTPTransport(c *http.Client) *FastHTTPTransport {
+	if c == nil {
+		c = new(http.Client)
+	}
+
+	return &FastHTTPTransport{Client: c}
+}
+
+// NewNetHTTPTransport creates a new Transport based on the net/http.Client. The http.DefaultClient is used if c is
+// nil.
+func NewNetHTTPTransport(c *nethttp.Client) *NetHTTPTransport {
+	if c == nil {
+		c = nethttp.DefaultClient
+	}
+
+	return &NetHTTPTransport{Client: c}
+}
+
+// Do implements Transport interface.
+func (t *FastHTTPTransport) Do(ctx context.Context, r *TransportRequest) (int, []byte, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	req := http.AcquireRequest()
+	req.Header.SetUserAgent(userAgent)
+	req.Header.SetMethod(r.Method)
+	req.SetRequestURI(r.URL)
+
+	if r.ContentType != "" {
+		req.Header.SetContentType(r.ContentType)
+	}
+
+	switch body := r.Body.(type) {
+	case nil:
+	case *bytes.Buffer:
+		req.SetBody(body.Bytes())
+	case *bytes.Reader:
+		req.SetBodyStream(body, body.Len())
+	default:
+		req.SetBodyStream(body, -1)
+	}
+
+	type result struct {
+		status int
+		body   []byte
+		err    error
+	}
+
+	done := make(chan result, 1)
+
+	// NOTE(toby3d): fasthttp.Client does not support context, so the request is performed in the separated
+	// goroutine which owns req and resp until the client is done with it, even if ctx is canceled earlier.
+	go func() {
+		defer http.ReleaseRequest(req)
+
+		resp := http.AcquireResponse()
+		defer http.ReleaseResponse(resp)
+
+		var err error
+
+		if deadline, ok := ctx.Deadline(); ok {
+			err = t.Client.DoDeadline(req, resp, deadline)
+		} else {
+			err = t.Client.Do(req, resp)
+		}
+
+		if err != nil {
+			done <- result{err: err}
+
+			return
+		}
+
+		done <- result{status: resp.StatusCode(), body: append([]byte(nil), resp.Body()...)}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return 0, nil, ctx.Err()
+	case r := <-done:
+		return r.status, r.body, r.err
+	}
+}
+
+// Do implements Transport interface.
+func (t *NetHTTPTransport) Do(ctx context.Context, r *TransportRequest) (int, []byte, error) {
+	req, err := nethttp.NewRequest(r.Method, r.URL, r.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("User-Agent", userAgent)
+
+	if r.ContentType != "" {
+		req.Header.Set("Content-Type", r.ContentType)
+	}
+
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return resp.StatusCode, body, nil
+}
@@ -0,0 +1,101 @@
+package telegram
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	json "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	http "github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func TestNetHTTPTransport(t *testing.T) {
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		assert.Equal(t, "/bot123:abc/getMe", r.URL.Path)
+		assert.Equal(t, userAgent, r.UserAgent())
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"id":123,"is_bot":true,"first_name":"Bot"}}`))
+	}))
+	defer srv.Close()
+
+	b := Bot{AccessToken: "123:abc", marshler: json.ConfigFastest}
+	b.SetEndpoint(Endpoint{URL: srv.URL})
+	b.SetTransport(NewNetHTTPTransport(srv.Client()))
+
+	me, err := b.GetMe()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(123), me.ID)
+}
+
+func TestNewWithEndpointTransport(t *testing.T) {
+	aborted := make(chan struct{})
+
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if strings.HasSuffix(r.URL.Path, "/"+MethodGetUpdates) {
+			// NOTE(toby3d): server notices the closed connection only after the whole body is read.
+			_, _ = ioutil.ReadAll(r.Body)
+			<-r.Context().Done()
+			close(aborted)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"id":123,"is_bot":true,"first_name":"Bot"}}`))
+	}))
+	defer srv.Close()
+
+	b, err := NewWithEndpoint("123:abc", Endpoint{URL: srv.URL})
+	assert.NoError(t, err)
+	assert.IsType(t, new(NetHTTPTransport), b.transport)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err = b.GetUpdatesContext(ctx, &GetUpdates{Timeout: 60})
+	assert.Error(t, err)
+
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Error("getUpdates request is not aborted on the server side")
+	}
+}
+
+func TestFastHTTPTransport(t *testing.T) {
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+
+	go http.Serve(ln, func(ctx *http.RequestCtx) {
+		ctx.SetStatusCode(http.StatusCreated)
+		ctx.SetBody(ctx.PostBody())
+	})
+
+	tr := NewFastHTTPTransport(&http.Client{Dial: func(string) (net.Conn, error) { return ln.Dial() }})
+
+	t.Run("valid", func(t *testing.T) {
+		status, body, err := tr.Do(context.Background(), &TransportRequest{
+			Method: http.MethodPost,
+			URL:    "http://telegram.test/",
+			Body:   strings.NewReader("hello"),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, status)
+		assert.Equal(t, "hello", string(body))
+	})
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, _, err := tr.Do(ctx, &TransportRequest{Method: http.MethodPost, URL: "http://telegram.test/"})
+		assert.Equal(t, context.Canceled, err)
+	})
+}
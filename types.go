@@ -2,11 +2,13 @@ package telegram
 
 import (
 	"encoding/json"
-	"os"
+	"io"
+	"net/url"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	http "github.com/valyala/fasthttp"
 	"golang.org/x/text/language"
@@ -21,11 +23,11 @@ type (
 
 	// Response represents a response from the Telegram API with the result  stored raw. If ok equals true, the request was successful, and the result  of the query can be found in the result field. In case of an unsuccessful  request, ok equals false, and the error is explained in the error field.
 	Response struct {
-		Description string                `json:"description,omitempty"`
-		ErrorCode   int                   `json:"error_code,omitempty"`
-		Ok          bool                  `json:"ok"`
-		Parameters  []*ResponseParameters `json:"parameters,omitempty"`
-		Result      json.RawMessage       `json:"result,omitempty"`
+		Description string              `json:"description,omitempty"`
+		ErrorCode   int                 `json:"error_code,omitempty"`
+		Ok          bool                `json:"ok"`
+		Parameters  *ResponseParameters `json:"parameters,omitempty"`
+		Result      json.RawMessage     `json:"result,omitempty"`
 	}
 
 	// User represents a Telegram user or bot.
@@ -1312,9 +1314,20 @@ type (
 
 	// InputFile represents the contents of a file to be uploaded. Must be poste using multipart/form-data in the usual way that files are uploaded via the browser.
 	InputFile struct {
-		ID         string    `json:"-"`
-		URI        *http.URI `json:"-"`
-		Attachment *os.File  `json:"-"`
+		ID  string    `json:"-"`
+		URI *http.URI `json:"-"`
+
+		// HTTP URL of the file for Telegram to get it from the Internet, it's an alternative to URI.
+		URL *url.URL `json:"-"`
+
+		// Contents of the uploaded file, *os.File or any other io.Reader.
+		Attachment io.Reader `json:"-"`
+
+		// Name of the uploaded file. Required if Attachment is not an *os.File.
+		Name string `json:"-"`
+
+		// Optional. MIME type of the uploaded file, application/octet-stream by default.
+		MimeType string `json:"-"`
 	}
 
 	Photo []*PhotoSize
@@ -1421,12 +1434,12 @@ func (m Message) RawCommand() string {
 		return ""
 	}
 
-	return string([]rune(m.Text)[1:m.Entities[0].Length])
+	return strings.TrimPrefix(m.Entities[0].Substring(m.Text), "/")
 }
 
 // HasCommandArgument checks that the current command message contains argument.
 func (m Message) HasCommandArgument() bool {
-	return m.IsCommand() && m.Entities[0].IsBotCommand() && len([]rune(m.Text)) != m.Entities[0].Length
+	return m.IsCommand() && m.Entities[0].IsBotCommand() && utf16Len(m.Text) > m.Entities[0].Length
 }
 
 // CommandArgument returns raw command argument.
@@ -1435,7 +1448,15 @@ func (m Message) CommandArgument() string {
 		return ""
 	}
 
-	return string([]rune(m.Text)[m.Entities[0].Length+1:])
+	command := m.Entities[0].Substring(m.Text)
+	if command == "" {
+		return ""
+	}
+
+	// NOTE(toby3d): skip the separator between command and argument.
+	_, size := utf8.DecodeRuneInString(m.Text[len(command):])
+
+	return m.Text[len(command)+size:]
 }
 
 // IsReply checks that the current message is a reply on other message.
@@ -1555,6 +1576,26 @@ func (m Message) HasEntities() bool { return len(m.Entities) > 0 }
 // HasCaptionEntities checks that the current media contains entities in caption.
 func (m Message) HasCaptionEntities() bool { return len(m.CaptionEntities) > 0 }
 
+// EntityText returns the part of text of the current message marked by the entity with index i in Entities, or
+// empty string if there is no such entity.
+func (m Message) EntityText(i int) string {
+	if i < 0 || i >= len(m.Entities) || m.Entities[i] == nil {
+		return ""
+	}
+
+	return m.Entities[i].Substring(m.Text)
+}
+
+// CaptionEntityText returns the part of caption of the current media marked by the entity with index i in
+// CaptionEntities, or empty string if there is no such entity.
+func (m Message) CaptionEntityText(i int) string {
+	if i < 0 || i >= len(m.CaptionEntities) || m.CaptionEntities[i] == nil {
+		return ""
+	}
+
+	return m.CaptionEntities[i].Substring(m.Caption)
+}
+
 // HasMentions checks that the current message contains mentions.
 func (m Message) HasMentions() bool {
 	if !m.HasEntities() {
@@ -1650,21 +1691,47 @@ func (e MessageEntity) IsUnderline() bool { return strings.EqualFold(e.Type, Ent
 // IsURL checks that the current entity is a URL.
 func (e MessageEntity) IsURL() bool { return strings.EqualFold(e.Type, EntityURL) }
 
+// Substring returns the part of message text (or caption) marked by the current entity, with offset and length
+// counted in UTF-16 code units. It returns empty string if text does not contain the entity.
+func (e MessageEntity) Substring(text string) string {
+	src, _ := utf16Slice(text, e.Offset, e.Length)
+
+	return src
+}
+
 // ParseURL selects URL from message text/caption and parse it as fasthttp.URI.
 func (e MessageEntity) ParseURL(text string) *http.URI {
 	if !e.IsURL() || text == "" {
 		return nil
 	}
 
+	src, ok := utf16Slice(text, e.Offset, e.Length)
+	if !ok {
+		return nil
+	}
+
 	link := http.AcquireURI()
-	to := e.Offset + e.Length
-	src := []rune(text)
 
-	if len(src) < to {
+	link.Update(src)
+
+	return link
+}
+
+// ParseNetURL is like ParseURL but returns net/url.URL.
+func (e MessageEntity) ParseNetURL(text string) *url.URL {
+	if !e.IsURL() || text == "" {
 		return nil
 	}
 
-	link.Update(string(src[e.Offset:to]))
+	src, ok := utf16Slice(text, e.Offset, e.Length)
+	if !ok {
+		return nil
+	}
+
+	link, err := url.Parse(src)
+	if err != nil {
+		return nil
+	}
 
 	return link
 }
@@ -1682,6 +1749,20 @@ func (e MessageEntity) TextLink() *http.URI {
 	return link
 }
 
+// TextLinkNetURL is like TextLink but returns net/url.URL.
+func (e MessageEntity) TextLinkNetURL() *url.URL {
+	if !e.IsTextLink() || e.URL == "" {
+		return nil
+	}
+
+	link, err := url.Parse(e.URL)
+	if err != nil {
+		return nil
+	}
+
+	return link
+}
+
 func (a Audio) FullName(separator string) (name string) {
 	if a.HasPerformer() {
 		if separator == "" {
@@ -1828,31 +1909,48 @@ func (InputMediaVideo) isAlbumMedia() {}
 
 func (f InputFile) IsFileID() bool { return f.ID != "" }
 
-func (f InputFile) IsURI() bool { return f.URI != nil }
+func (f InputFile) IsURI() bool { return f.URI != nil || f.URL != nil }
 
 func (f InputFile) IsAttachment() bool { return f.Attachment != nil }
 
+// FileName returns name of the uploaded file from Name or from the name of Attachment, if it's a file.
+func (f InputFile) FileName() string {
+	if f.Name != "" {
+		return filepath.Base(f.Name)
+	}
+
+	if file, ok := f.Attachment.(interface{ Name() string }); ok {
+		return filepath.Base(file.Name())
+	}
+
+	return ""
+}
+
 // MarshalJSON marshals InputFile into single JSON value.
 func (f InputFile) MarshalJSON() ([]byte, error) {
+	value := f.formValue()
+	if value == "" {
+		return nil, nil
+	}
+
+	return json.Marshal(value)
+}
+
+// formValue returns the value of the file in form fields as is: file ID, URL or attach:// URI of the attachment.
+func (f *InputFile) formValue() string {
 	switch {
+	case f == nil:
+		return ""
 	case f.IsFileID():
-		return []byte(f.ID), nil
-	case f.IsURI():
-		return f.URI.FullURI(), nil
+		return f.ID
+	case f.URI != nil:
+		return string(f.URI.FullURI())
+	case f.URL != nil:
+		return f.URL.String()
 	case f.IsAttachment():
-		_, fileName := filepath.Split(f.Attachment.Name())
-
-		u := http.AcquireURI()
-		defer http.ReleaseURI(u)
-		u.SetScheme(SchemeAttach)
-		u.SetHost(fileName)
-		u.SetPathBytes(nil)
-
-		uri := u.FullURI() // NOTE(toby3d): remove slash on the end
-
-		return uri[:len(uri)-1], nil
+		return SchemeAttach + "://" + f.FileName()
 	default:
-		return nil, nil
+		return ""
 	}
 }
 
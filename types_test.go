@@ -1,13 +1,17 @@
 package telegram
 
 import (
+	"encoding/json"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/assert"
 	http "github.com/valyala/fasthttp"
 	"golang.org/x/text/language"
@@ -286,6 +290,13 @@ func TestMessageCommand(t *testing.T) {
 			Entities: []*MessageEntity{{Type: EntityBotCommand, Length: len(CommandStart) + 1}},
 		},
 		expResult: CommandStart,
+	}, {
+		name: "username",
+		message: Message{
+			Text:     "/start@toby3dBot 👋",
+			Entities: []*MessageEntity{{Type: EntityBotCommand, Length: 16}},
+		},
+		expResult: CommandStart,
 	}, {
 		name: "other",
 		message: Message{
@@ -316,6 +327,13 @@ func TestMessageRawCommand(t *testing.T) {
 			Entities: []*MessageEntity{{Type: EntityBotCommand, Length: len(CommandStart) + 1}},
 		},
 		expResult: CommandStart,
+	}, {
+		name: "username",
+		message: Message{
+			Text:     "/start@toby3dBot 👋",
+			Entities: []*MessageEntity{{Type: EntityBotCommand, Length: 16}},
+		},
+		expResult: CommandStart + "@toby3dBot",
 	}, {
 		name: "other",
 		message: Message{
@@ -383,6 +401,13 @@ func TestMessageCommandArgument(t *testing.T) {
 			Entities: []*MessageEntity{{Type: EntityBotCommand, Length: len(CommandStart) + 1}},
 		},
 		expResult: "example",
+	}, {
+		name: "unicode",
+		message: Message{
+			Text:     "/start@toby3dBot 👋 привет",
+			Entities: []*MessageEntity{{Type: EntityBotCommand, Length: 16}},
+		},
+		expResult: "👋 привет",
 	}, {
 		name: "false",
 		message: Message{
@@ -888,6 +913,44 @@ func TestMessageHasMentions(t *testing.T) {
 	}
 }
 
+func TestMessageEntityText(t *testing.T) {
+	mention := &MessageEntity{Type: EntityMention, Offset: 3, Length: 7}
+	link := &MessageEntity{Type: EntityTextLink, Offset: 8, Length: 5, URL: "https://toby3d.me/"}
+	m := Message{
+		Text:            "👋 @toby3d, 𝄞 hi",
+		Entities:        []*MessageEntity{mention},
+		Caption:         "Привет, 🌍world!",
+		CaptionEntities: []*MessageEntity{link},
+	}
+
+	assert.Equal(t, "@toby3d", m.EntityText(0))
+	assert.Equal(t, "🌍wor", m.CaptionEntityText(0))
+	assert.Empty(t, m.EntityText(1), "missing entity")
+	assert.Empty(t, m.CaptionEntityText(-1), "missing entity")
+}
+
+func TestMessageEntitySubstring(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		entity    MessageEntity
+		text      string
+		expResult string
+	}{
+		{name: "ascii", entity: MessageEntity{Offset: 6, Length: 5}, text: "hello world", expResult: "world"},
+		{name: "cyrillic", entity: MessageEntity{Offset: 8, Length: 3}, text: "привет, мир", expResult: "мир"},
+		{name: "emoji", entity: MessageEntity{Offset: 3, Length: 2}, text: "👋 🌍!", expResult: "🌍"},
+		{name: "end", entity: MessageEntity{Offset: 2, Length: 0}, text: "👋", expResult: ""},
+		{name: "surrogate pair", entity: MessageEntity{Offset: 1, Length: 1}, text: "👋", expResult: ""},
+		{name: "out of range", entity: MessageEntity{Offset: 3, Length: 5}, text: "hello", expResult: ""},
+		{name: "negative", entity: MessageEntity{Offset: -1, Length: 1}, text: "hello", expResult: ""},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expResult, tc.entity.Substring(tc.text))
+		})
+	}
+}
+
 func TestMessageHasCaptionMentions(t *testing.T) {
 	for _, tc := range []struct {
 		name      string
@@ -1396,6 +1459,11 @@ func TestMessageEntityParseURL(t *testing.T) {
 		entity:    MessageEntity{Type: EntityURL, Length: len(link.String())},
 		text:      link.String(),
 		expResult: link,
+	}, {
+		name:      "after emoji",
+		entity:    MessageEntity{Type: EntityURL, Offset: 5, Length: len(link.String())},
+		text:      "🔗👉 " + link.String() + " 👈",
+		expResult: link,
 	}, {
 		name:      "other",
 		entity:    MessageEntity{Type: EntityTextLink},
@@ -1958,6 +2026,40 @@ func TestInputFileIsAttachment(t *testing.T) {
 	})
 }
 
+func TestInputFileFileName(t *testing.T) {
+	file, err := ioutil.TempFile(os.TempDir(), "photo_*.jpeg")
+	assert.NoError(t, err)
+
+	defer os.RemoveAll(file.Name())
+
+	for _, tc := range []struct {
+		name      string
+		inputFile InputFile
+		expResult string
+	}{{
+		name:      "file",
+		inputFile: InputFile{Attachment: file},
+		expResult: filepath.Base(file.Name()),
+	}, {
+		name:      "reader",
+		inputFile: InputFile{Attachment: strings.NewReader("abc"), Name: "chart.png"},
+		expResult: "chart.png",
+	}, {
+		name:      "override",
+		inputFile: InputFile{Attachment: file, Name: "/tmp/photo.jpeg"},
+		expResult: "photo.jpeg",
+	}, {
+		name:      "empty",
+		inputFile: InputFile{Attachment: strings.NewReader("abc")},
+		expResult: "",
+	}} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expResult, tc.inputFile.FileName())
+		})
+	}
+}
+
 func TestInputFileMarshalJSON(t *testing.T) {
 	u := http.AcquireURI()
 	defer http.ReleaseURI(u)
@@ -1970,6 +2072,9 @@ func TestInputFileMarshalJSON(t *testing.T) {
 
 	_, fileName := filepath.Split(file.Name())
 
+	link, err := url.Parse("https://toby3d.me/image.jpeg")
+	assert.NoError(t, err)
+
 	for _, tc := range []struct {
 		name      string
 		inputFile InputFile
@@ -1977,15 +2082,23 @@ func TestInputFileMarshalJSON(t *testing.T) {
 	}{{
 		name:      "id",
 		inputFile: InputFile{ID: "abc"},
-		expResult: "abc",
+		expResult: `"abc"`,
 	}, {
 		name:      "uri",
 		inputFile: InputFile{URI: u},
-		expResult: u.String(),
+		expResult: `"` + u.String() + `"`,
+	}, {
+		name:      "url",
+		inputFile: InputFile{URL: link},
+		expResult: `"https://toby3d.me/image.jpeg"`,
 	}, {
 		name:      "attach",
 		inputFile: InputFile{Attachment: file},
-		expResult: SchemeAttach + "://" + fileName,
+		expResult: `"` + SchemeAttach + "://" + fileName + `"`,
+	}, {
+		name:      "reader",
+		inputFile: InputFile{Attachment: strings.NewReader("abc"), Name: "chart.png"},
+		expResult: `"` + SchemeAttach + "://chart.png" + `"`,
 	}, {
 		name:      "empty",
 		inputFile: InputFile{},
@@ -1996,6 +2109,14 @@ func TestInputFileMarshalJSON(t *testing.T) {
 			src, err := tc.inputFile.MarshalJSON()
 			assert.NoError(t, err)
 			assert.Equal(t, tc.expResult, string(src))
+
+			if tc.expResult == "" {
+				return
+			}
+
+			media, err := jsoniter.ConfigFastest.Marshal(InputMediaPhoto{Type: TypePhoto, Media: &tc.inputFile})
+			assert.NoError(t, err)
+			assert.True(t, json.Valid(media), string(media))
 		})
 	}
 }
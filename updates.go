@@ -1,7 +1,9 @@
 package telegram
 
 import (
+	"context"
 	"net"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -27,7 +29,7 @@ type (
 		ChannelPost *Message `json:"channel_post,omitempty"`
 
 		// New version of a channel post that is known to the bot and was edited
-		EditedChannelPost *Message `json:"adited_channel_post,omitempty"`
+		EditedChannelPost *Message `json:"edited_channel_post,omitempty"`
 
 		// New incoming inline query
 		InlineQuery *InlineQuery `json:"inline_query,omitempty"`
@@ -127,6 +129,11 @@ type (
 
 		// Pass True to drop all pending updates
 		DropPendingUpdates bool `json:"drop_pending_updates,omitempty"`
+
+		// A secret token to be sent in a header “X-Telegram-Bot-Api-Secret-Token” in every webhook request,
+		// 1-256 characters. Only characters A-Z, a-z, 0-9, _ and - are allowed. The header is useful to ensure
+		// that the request comes from a webhook set by you.
+		SecretToken string `json:"secret_token,omitempty"`
 	}
 
 	DeleteWebhook struct {
@@ -136,11 +143,55 @@ type (
 
 	// UpdatesChannel represents channel for incoming updates.
 	UpdatesChannel chan *Update
+
+	// UpdateKind represents a kind of the Update, one for each of its optional fields.
+	UpdateKind uint8
+)
+
+// UpdateKind represents available and supported kinds of updates in order of Update fields.
+const (
+	KindUnknown UpdateKind = iota
+	KindMessage
+	KindEditedMessage
+	KindChannelPost
+	KindEditedChannelPost
+	KindInlineQuery
+	KindChosenInlineResult
+	KindCallbackQuery
+	KindShippingQuery
+	KindPreCheckoutQuery
+	KindPoll
+	KindPollAnswer
+	KindMyChatMember
+	KindChatMember
 )
 
+// updateKinds contains names of update kinds for allowed_updates.
+var updateKinds = [...]string{
+	KindUnknown:            "",
+	KindMessage:            UpdateMessage,
+	KindEditedMessage:      UpdateEditedMessage,
+	KindChannelPost:        UpdateChannelPost,
+	KindEditedChannelPost:  UpdateEditedChannelPost,
+	KindInlineQuery:        UpdateInlineQuery,
+	KindChosenInlineResult: UpdateChosenInlineResult,
+	KindCallbackQuery:      UpdateCallbackQuery,
+	KindShippingQuery:      UpdateShippingQuery,
+	KindPreCheckoutQuery:   UpdatePreCheckoutQuery,
+	KindPoll:               UpdatePoll,
+	KindPollAnswer:         UpdatePollAnswer,
+	KindMyChatMember:       UpdateMyChatMember,
+	KindChatMember:         UpdateChatMember,
+}
+
 // GetUpdates receive incoming updates using long polling. An Array of Update objects is returned.
 func (b Bot) GetUpdates(p *GetUpdates) ([]*Update, error) {
-	src, err := b.Do(MethodGetUpdates, p)
+	return b.GetUpdatesContext(context.Background(), p)
+}
+
+// GetUpdatesContext is like GetUpdates but uses ctx for cancellation and deadlines.
+func (b Bot) GetUpdatesContext(ctx context.Context, p *GetUpdates) ([]*Update, error) {
+	src, err := b.DoContext(ctx, MethodGetUpdates, p)
 	if err != nil {
 		return nil, err
 	}
@@ -155,21 +206,36 @@ func (b Bot) GetUpdates(p *GetUpdates) ([]*Update, error) {
 
 // SetWebhook specify a url and receive incoming updates via an outgoing webhook. Whenever there is an update for the bot, we will send an HTTPS POST request to the specified url, containing a JSON-serialized Update. In case of an unsuccessful request, we will give up after a reasonable amount of attempts. Returns true.
 //
-// If you'd like to make sure that the Webhook request comes from Telegram, we recommend using a secret path in the URL, e.g. https://www.example.com/<token>. Since nobody else knows your bot‘s token, you can be pretty sure it’s us.
+// If you'd like to make sure that the Webhook request comes from Telegram, we recommend using a secret token in the SecretToken parameter, which is sent back in the X-Telegram-Bot-Api-Secret-Token header of each request.
 func (b Bot) SetWebhook(p SetWebhook) (ok bool, err error) {
+	return b.SetWebhookContext(context.Background(), p)
+}
+
+// SetWebhookContext is like SetWebhook but uses ctx for cancellation and deadlines.
+func (b Bot) SetWebhookContext(ctx context.Context, p SetWebhook) (ok bool, err error) {
 	if p.Certificate.IsAttachment() {
-		_, err := b.Upload(MethodSetWebhook, map[string]string{
+		params := map[string]string{
 			"url":                  p.URL,
 			"ip_address":           p.IpAddress.String(),
 			"max_connections":      strconv.Itoa(p.MaxConnections),
 			"allowed_updates":      strings.Join(p.AllowedUpdates, ","),
 			"drop_pending_updates": strconv.FormatBool(p.DropPendingUpdates),
-		}, &p.Certificate)
+		}
+
+		if p.SecretToken != "" {
+			params["secret_token"] = p.SecretToken
+		}
+
+		_, err := b.UploadContext(ctx, MethodSetWebhook, params, &p.Certificate)
 
 		return err == nil, err
 	}
 
-	src, err := b.Do(MethodSetWebhook, p)
+	// NOTE(toby3d): certificate can be only uploaded, so hide the empty InputFile which can't be marshaled.
+	src, err := b.DoContext(ctx, MethodSetWebhook, struct {
+		SetWebhook
+		Certificate *InputFile `json:"certificate,omitempty"`
+	}{SetWebhook: p})
 	if err != nil {
 		return ok, err
 	}
@@ -183,7 +249,12 @@ func (b Bot) SetWebhook(p SetWebhook) (ok bool, err error) {
 
 // DeleteWebhook remove webhook integration if you decide to switch back to getUpdates. Returns True on success. Requires no parameters.
 func (b Bot) DeleteWebhook(p DeleteWebhook) (ok bool, err error) {
-	src, err := b.Do(MethodDeleteWebhook, p)
+	return b.DeleteWebhookContext(context.Background(), p)
+}
+
+// DeleteWebhookContext is like DeleteWebhook but uses ctx for cancellation and deadlines.
+func (b Bot) DeleteWebhookContext(ctx context.Context, p DeleteWebhook) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodDeleteWebhook, p)
 	if err != nil {
 		return ok, err
 	}
@@ -197,7 +268,12 @@ func (b Bot) DeleteWebhook(p DeleteWebhook) (ok bool, err error) {
 
 // GetWebhookInfo get current webhook status. Requires no parameters. On success, returns a WebhookInfo object. If the bot is using getUpdates, will return an object with the url field empty.
 func (b Bot) GetWebhookInfo() (*WebhookInfo, error) {
-	src, err := b.Do(MethodGetWebhookInfo, nil)
+	return b.GetWebhookInfoContext(context.Background())
+}
+
+// GetWebhookInfoContext is like GetWebhookInfo but uses ctx for cancellation and deadlines.
+func (b Bot) GetWebhookInfoContext(ctx context.Context) (*WebhookInfo, error) {
+	src, err := b.DoContext(ctx, MethodGetWebhookInfo, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -240,32 +316,94 @@ func (u Update) IsPreCheckoutQuery() bool { return u.PreCheckoutQuery != nil }
 // IsPoll checks that the current update is a poll update.
 func (u Update) IsPoll() bool { return u.Poll != nil }
 
-// Type return update type for current update.
-func (u Update) Type() string {
+// IsPollAnswer checks that the current update is an answer in the non-anonymous poll.
+func (u Update) IsPollAnswer() bool { return u.PollAnswer != nil }
+
+// IsMyChatMember checks that the current update is a status change of the bot itself.
+func (u Update) IsMyChatMember() bool { return u.MyChatMember != nil }
+
+// IsChatMember checks that the current update is a status change of the chat member.
+func (u Update) IsChatMember() bool { return u.ChatMember != nil }
+
+// Kind returns kind of the current update.
+func (u Update) Kind() UpdateKind {
 	switch {
-	case u.IsCallbackQuery():
-		return UpdateCallbackQuery
+	case u.IsMessage():
+		return KindMessage
+	case u.IsEditedMessage():
+		return KindEditedMessage
 	case u.IsChannelPost():
-		return UpdateChannelPost
-	case u.IsChosenInlineResult():
-		return UpdateChosenInlineResult
+		return KindChannelPost
 	case u.IsEditedChannelPost():
-		return UpdateEditedChannelPost
-	case u.IsEditedMessage():
-		return UpdateEditedMessage
+		return KindEditedChannelPost
 	case u.IsInlineQuery():
-		return UpdateInlineQuery
-	case u.IsMessage():
-		return UpdateMessage
-	case u.IsPreCheckoutQuery():
-		return UpdatePreCheckoutQuery
+		return KindInlineQuery
+	case u.IsChosenInlineResult():
+		return KindChosenInlineResult
+	case u.IsCallbackQuery():
+		return KindCallbackQuery
 	case u.IsShippingQuery():
-		return UpdateShippingQuery
+		return KindShippingQuery
+	case u.IsPreCheckoutQuery():
+		return KindPreCheckoutQuery
 	case u.IsPoll():
-		return UpdatePoll
+		return KindPoll
+	case u.IsPollAnswer():
+		return KindPollAnswer
+	case u.IsMyChatMember():
+		return KindMyChatMember
+	case u.IsChatMember():
+		return KindChatMember
 	default:
+		return KindUnknown
+	}
+}
+
+// Payload returns the value of the current update field, like *Message or *CallbackQuery, or nil for unknown
+// update.
+func (u Update) Payload() interface{} {
+	switch u.Kind() {
+	case KindMessage:
+		return u.Message
+	case KindEditedMessage:
+		return u.EditedMessage
+	case KindChannelPost:
+		return u.ChannelPost
+	case KindEditedChannelPost:
+		return u.EditedChannelPost
+	case KindInlineQuery:
+		return u.InlineQuery
+	case KindChosenInlineResult:
+		return u.ChosenInlineResult
+	case KindCallbackQuery:
+		return u.CallbackQuery
+	case KindShippingQuery:
+		return u.ShippingQuery
+	case KindPreCheckoutQuery:
+		return u.PreCheckoutQuery
+	case KindPoll:
+		return u.Poll
+	case KindPollAnswer:
+		return u.PollAnswer
+	case KindMyChatMember:
+		return u.MyChatMember
+	case KindChatMember:
+		return u.ChatMember
+	default:
+		return nil
+	}
+}
+
+// Type return update type for current update.
+func (u Update) Type() string { return u.Kind().String() }
+
+// String returns name of the update kind as in allowed_updates, or empty string for unknown kind.
+func (k UpdateKind) String() string {
+	if int(k) >= len(updateKinds) {
 		return ""
 	}
+
+	return updateKinds[k]
 }
 
 func (w WebhookInfo) LastErrorTime() time.Time { return time.Unix(w.LastErrorDate, 0) }
@@ -282,3 +420,17 @@ func (w WebhookInfo) URI() *http.URI {
 
 	return u
 }
+
+// NetURL is like URI but returns net/url.URL.
+func (w WebhookInfo) NetURL() *url.URL {
+	if !w.HasURL() {
+		return nil
+	}
+
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return nil
+	}
+
+	return u
+}
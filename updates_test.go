@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	json "github.com/json-iterator/go"
 	"github.com/stretchr/testify/assert"
 	http "github.com/valyala/fasthttp"
 )
@@ -118,6 +119,39 @@ func TestUpdateIsPoll(t *testing.T) {
 	})
 }
 
+func TestUpdateIsPollAnswer(t *testing.T) {
+	t.Run("true", func(t *testing.T) {
+		u := Update{PollAnswer: &PollAnswer{PollID: "abc"}}
+		assert.True(t, u.IsPollAnswer())
+	})
+	t.Run("false", func(t *testing.T) {
+		u := Update{}
+		assert.False(t, u.IsPollAnswer())
+	})
+}
+
+func TestUpdateIsMyChatMember(t *testing.T) {
+	t.Run("true", func(t *testing.T) {
+		u := Update{MyChatMember: &ChatMemberUpdated{Date: 42}}
+		assert.True(t, u.IsMyChatMember())
+	})
+	t.Run("false", func(t *testing.T) {
+		u := Update{}
+		assert.False(t, u.IsMyChatMember())
+	})
+}
+
+func TestUpdateIsChatMember(t *testing.T) {
+	t.Run("true", func(t *testing.T) {
+		u := Update{ChatMember: &ChatMemberUpdated{Date: 42}}
+		assert.True(t, u.IsChatMember())
+	})
+	t.Run("false", func(t *testing.T) {
+		u := Update{}
+		assert.False(t, u.IsChatMember())
+	})
+}
+
 func TestUpdateType(t *testing.T) {
 	for _, tc := range []struct {
 		name      string
@@ -163,6 +197,18 @@ func TestUpdateType(t *testing.T) {
 		name:      UpdateShippingQuery,
 		update:    Update{ShippingQuery: &ShippingQuery{ID: "abc"}},
 		expResult: UpdateShippingQuery,
+	}, {
+		name:      UpdatePollAnswer,
+		update:    Update{PollAnswer: &PollAnswer{PollID: "abc"}},
+		expResult: UpdatePollAnswer,
+	}, {
+		name:      UpdateMyChatMember,
+		update:    Update{MyChatMember: &ChatMemberUpdated{Date: 42}},
+		expResult: UpdateMyChatMember,
+	}, {
+		name:      UpdateChatMember,
+		update:    Update{ChatMember: &ChatMemberUpdated{Date: 42}},
+		expResult: UpdateChatMember,
 	}, {
 		name:      "other",
 		update:    Update{},
@@ -175,6 +221,54 @@ func TestUpdateType(t *testing.T) {
 	}
 }
 
+func TestUpdateKind(t *testing.T) {
+	message := &Message{ID: 42}
+	member := &ChatMemberUpdated{Date: 42}
+
+	for _, tc := range []struct {
+		name       string
+		update     Update
+		expKind    UpdateKind
+		expPayload interface{}
+	}{{
+		name:       UpdateMessage,
+		update:     Update{Message: message},
+		expKind:    KindMessage,
+		expPayload: message,
+	}, {
+		name:       UpdateEditedChannelPost,
+		update:     Update{EditedChannelPost: message},
+		expKind:    KindEditedChannelPost,
+		expPayload: message,
+	}, {
+		name:       UpdateChatMember,
+		update:     Update{ChatMember: member},
+		expKind:    KindChatMember,
+		expPayload: member,
+	}, {
+		name:       "other",
+		update:     Update{},
+		expKind:    KindUnknown,
+		expPayload: nil,
+	}} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expKind, tc.update.Kind())
+			assert.Equal(t, tc.expPayload, tc.update.Payload())
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		// NOTE(toby3d): each kind must be named as JSON field of the Update.
+		for kind := KindMessage; kind <= KindChatMember; kind++ {
+			u := new(Update)
+			assert.NoError(t, json.Unmarshal([]byte(`{"update_id":1,"`+kind.String()+`":{}}`), u))
+			assert.Equal(t, kind, u.Kind(), kind.String())
+			assert.NotNil(t, u.Payload(), kind.String())
+		}
+	})
+}
+
 func TestWebhookInfoLastErrorTime(t *testing.T) {
 	now := time.Now().Round(time.Second)
 	wi := WebhookInfo{LastErrorDate: now.Unix()}
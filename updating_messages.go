@@ -1,5 +1,7 @@
 package telegram
 
+import "context"
+
 type (
 	// EditMessageTextParameters represents data for EditMessageText method.
 	EditMessageText struct {
@@ -106,7 +108,12 @@ func NewEditText(text string) EditMessageText {
 
 // EditMessageText edit text and game messages sent by the bot or via the bot (for inline bots). On success, if edited message is sent by the bot, the edited Message is returned, otherwise True is returned.
 func (b Bot) EditMessageText(p EditMessageText) (*Message, error) {
-	src, err := b.Do(MethodEditMessageText, p)
+	return b.EditMessageTextContext(context.Background(), p)
+}
+
+// EditMessageTextContext is like EditMessageText but uses ctx for cancellation and deadlines.
+func (b Bot) EditMessageTextContext(ctx context.Context, p EditMessageText) (*Message, error) {
+	src, err := b.DoContext(ctx, MethodEditMessageText, p)
 	if err != nil {
 		return nil, err
 	}
@@ -121,7 +128,12 @@ func (b Bot) EditMessageText(p EditMessageText) (*Message, error) {
 
 // EditMessageCaption edit captions of messages sent by the bot or via the bot (for inline bots). On success, if edited message is sent by the bot, the edited Message is returned, otherwise True is returned.
 func (b Bot) EditMessageCaption(p EditMessageCaption) (*Message, error) {
-	src, err := b.Do(MethodEditMessageCaption, p)
+	return b.EditMessageCaptionContext(context.Background(), p)
+}
+
+// EditMessageCaptionContext is like EditMessageCaption but uses ctx for cancellation and deadlines.
+func (b Bot) EditMessageCaptionContext(ctx context.Context, p EditMessageCaption) (*Message, error) {
+	src, err := b.DoContext(ctx, MethodEditMessageCaption, p)
 	if err != nil {
 		return nil, err
 	}
@@ -142,7 +154,12 @@ func NewEditMedia(media InputMedia) EditMessageMedia {
 
 // EditMessageMedia edit audio, document, photo, or video messages. If a message is a part of a message album, then it can be edited only to a photo or a video. Otherwise, message type can be changed arbitrarily. When inline message is edited, new file can't be uploaded. Use previously uploaded file via its file_id or specify a URL. On success, if the edited message was sent by the bot, the edited Message is returned, otherwise True is returned.
 func (b Bot) EditMessageMedia(p EditMessageMedia) (*Message, error) {
-	src, err := b.Do(MethodEditMessageMedia, p)
+	return b.EditMessageMediaContext(context.Background(), p)
+}
+
+// EditMessageMediaContext is like EditMessageMedia but uses ctx for cancellation and deadlines.
+func (b Bot) EditMessageMediaContext(ctx context.Context, p EditMessageMedia) (*Message, error) {
+	src, err := b.DoContext(ctx, MethodEditMessageMedia, p)
 	if err != nil {
 		return nil, err
 	}
@@ -157,7 +174,12 @@ func (b Bot) EditMessageMedia(p EditMessageMedia) (*Message, error) {
 
 // EditMessageReplyMarkup edit only the reply markup of messages sent by the bot or via the bot (for inline bots). On success, if edited message is sent by the bot, the edited Message is returned, otherwise True is returned.
 func (b Bot) EditMessageReplyMarkup(p EditMessageReplyMarkup) (*Message, error) {
-	src, err := b.Do(MethodEditMessageReplyMarkup, p)
+	return b.EditMessageReplyMarkupContext(context.Background(), p)
+}
+
+// EditMessageReplyMarkupContext is like EditMessageReplyMarkup but uses ctx for cancellation and deadlines.
+func (b Bot) EditMessageReplyMarkupContext(ctx context.Context, p EditMessageReplyMarkup) (*Message, error) {
+	src, err := b.DoContext(ctx, MethodEditMessageReplyMarkup, p)
 	if err != nil {
 		return nil, err
 	}
@@ -179,7 +201,12 @@ func NewStopPoll(chatID ChatID, messageID int64) StopPoll {
 
 // StopPoll stop a poll which was sent by the bot. On success, the stopped Poll with the final results is returned.
 func (b Bot) StopPoll(p StopPoll) (*Poll, error) {
-	src, err := b.Do(MethodStopPoll, p)
+	return b.StopPollContext(context.Background(), p)
+}
+
+// StopPollContext is like StopPoll but uses ctx for cancellation and deadlines.
+func (b Bot) StopPollContext(ctx context.Context, p StopPoll) (*Poll, error) {
+	src, err := b.DoContext(ctx, MethodStopPoll, p)
 	if err != nil {
 		return nil, err
 	}
@@ -203,7 +230,12 @@ func (b Bot) StopPoll(p StopPoll) (*Poll, error) {
 //
 // Returns True on success.
 func (b Bot) DeleteMessage(p DeleteMessage) (ok bool, err error) {
-	src, err := b.Do(MethodDeleteMessage, p)
+	return b.DeleteMessageContext(context.Background(), p)
+}
+
+// DeleteMessageContext is like DeleteMessage but uses ctx for cancellation and deadlines.
+func (b Bot) DeleteMessageContext(ctx context.Context, p DeleteMessage) (ok bool, err error) {
+	src, err := b.DoContext(ctx, MethodDeleteMessage, p)
 	if err != nil {
 		return ok, err
 	}
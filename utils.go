@@ -1,6 +1,8 @@
 package telegram
 
 import (
+	"io"
+
 	jsoniter "github.com/json-iterator/go"
 	"golang.org/x/xerrors"
 )
@@ -19,9 +21,142 @@ func parseResponseError(marshler jsoniter.API, src []byte, dst interface{}) (err
 	respErr := new(Error)
 	respErr.Code = resp.ErrorCode
 	respErr.Description = resp.Description
+	respErr.Parameters = resp.Parameters
 	respErr.frame = xerrors.Caller(1)
 
-	copy(respErr.Parameters, resp.Parameters)
-
 	return respErr
 }
+
+// replaceField returns a copy of src JSON object with the value of key field replaced by val.
+func replaceField(marshler jsoniter.API, src []byte, key string, val []byte) ([]byte, error) {
+	iter := marshler.BorrowIterator(src)
+	defer marshler.ReturnIterator(iter)
+
+	stream := marshler.BorrowStream(nil)
+	defer marshler.ReturnStream(stream)
+
+	stream.WriteObjectStart()
+
+	first := true
+
+	iter.ReadObjectCB(func(iter *jsoniter.Iterator, field string) bool {
+		if !first {
+			stream.WriteMore()
+		}
+
+		first = false
+
+		stream.WriteObjectField(field)
+
+		if field != key {
+			stream.Write(iter.SkipAndReturnBytes())
+
+			return true
+		}
+
+		iter.Skip()
+		stream.Write(val)
+
+		return true
+	})
+
+	stream.WriteObjectEnd()
+
+	if iter.Error != nil && iter.Error != io.EOF {
+		return nil, iter.Error
+	}
+
+	return append([]byte(nil), stream.Buffer()...), nil
+}
+
+// prependField returns a copy of src JSON object with the key field inserted before all other fields.
+func prependField(marshler jsoniter.API, src []byte, key string, val []byte) ([]byte, error) {
+	iter := marshler.BorrowIterator(src)
+	defer marshler.ReturnIterator(iter)
+
+	stream := marshler.BorrowStream(nil)
+	defer marshler.ReturnStream(stream)
+
+	stream.WriteObjectStart()
+	stream.WriteObjectField(key)
+	stream.Write(val)
+
+	iter.ReadObjectCB(func(iter *jsoniter.Iterator, field string) bool {
+		if field == key {
+			iter.Skip()
+
+			return true
+		}
+
+		stream.WriteMore()
+		stream.WriteObjectField(field)
+		stream.Write(iter.SkipAndReturnBytes())
+
+		return true
+	})
+
+	stream.WriteObjectEnd()
+
+	if iter.Error != nil && iter.Error != io.EOF {
+		return nil, iter.Error
+	}
+
+	return append([]byte(nil), stream.Buffer()...), nil
+}
+
+// utf16Len returns length of s in UTF-16 code units, which are used by Telegram for entities offsets.
+func utf16Len(s string) (n int) {
+	for _, r := range s {
+		n += utf16RuneLen(r)
+	}
+
+	return n
+}
+
+// utf16Slice returns substring of s by offset and length in UTF-16 code units. It returns false if the range is
+// out of s or splits a surrogate pair.
+func utf16Slice(s string, offset, length int) (string, bool) {
+	if offset < 0 || length < 0 {
+		return "", false
+	}
+
+	start, end := -1, -1
+	units := 0
+
+	for i, r := range s {
+		if units == offset {
+			start = i
+		}
+
+		if units == offset+length {
+			end = i
+
+			break
+		}
+
+		units += utf16RuneLen(r)
+	}
+
+	if start < 0 && units == offset {
+		start = len(s)
+	}
+
+	if end < 0 && units == offset+length {
+		end = len(s)
+	}
+
+	if start < 0 || end < 0 {
+		return "", false
+	}
+
+	return s[start:end], true
+}
+
+// utf16RuneLen returns number of UTF-16 code units which encode r.
+func utf16RuneLen(r rune) int {
+	if r >= 0x10000 {
+		return 2 // NOTE(toby3d): encoded by surrogate pair
+	}
+
+	return 1
+}
@@ -0,0 +1,621 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	nethttp "net/http"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/kirillDanshin/dlog"
+	http "github.com/valyala/fasthttp"
+)
+
+type (
+	// Webhook receives incoming updates via an outgoing webhook until it will be stopped.
+	//
+	// Requests with invalid payloads are answered with the 400 Bad Request status, so they are visible in the
+	// LastErrorMessage of the WebhookInfo. Errors which can not be returned to the caller, like failed Serve of the
+	// server or invalid payloads, are reported into the error handler.
+	Webhook struct {
+		// Policy of handling incoming updates while the updates channel is full, QueueBlock by default.
+		QueuePolicy QueuePolicy
+
+		bot          *Bot
+		params       SetWebhook
+		path         []byte
+		networks     []*net.IPNet
+		ipHeader     string
+		errorHandler ErrorHandler
+		replyHandler ReplyHandler
+		offsets      *offsetTracker
+		srv          *http.Server
+		ln           net.Listener
+		idle         map[net.Conn]struct{}
+		updates      UpdatesChannel
+		mu           sync.RWMutex
+		started      bool
+		stopped      bool
+		stop         chan struct{}
+		wg           sync.WaitGroup
+		done         chan struct{}
+	}
+
+	// QueuePolicy describes behaviour of the Webhook when the updates channel is full.
+	QueuePolicy uint8
+
+	// ReplyHandler handles the update synchronously while Telegram waits for the webhook response and can return
+	// a single method call, which is sent back in the response body instead of the separate Bot API request.
+	// Result of such call is unknown, so use it for calls like sendMessage or answerCallbackQuery, which result is
+	// not needed. Returned call is not passed through interceptors and flood limiter, so callback queries must be
+	// answered under CallbackAnswerer.ReplyMiddleware instead of Middleware.
+	ReplyHandler func(ctx context.Context, u *Update) *Call
+)
+
+const (
+	// QueueBlock waits until the update will be read from the updates channel.
+	QueueBlock QueuePolicy = iota
+
+	// QueueDrop answers with the 200 OK status and drops the update.
+	QueueDrop
+
+	// QueueReject answers with the 503 Service Unavailable status, so Telegram will repeat the update later.
+	QueueReject
+)
+
+const (
+	// HeaderSecretToken is a header of webhook requests which contains SecretToken of the SetWebhook parameters.
+	HeaderSecretToken string = "X-Telegram-Bot-Api-Secret-Token"
+
+	// webhookMaxBodySize is a maximum size of the webhook request body, the same as the default of fasthttp.Server.
+	webhookMaxBodySize int64 = http.DefaultMaxRequestBodySize
+)
+
+// telegramNetworks contains subnets which are used by Telegram for webhook requests.
+//
+// See https://core.telegram.org/bots/webhooks#the-short-version
+var telegramNetworks = [...]string{"149.154.160.0/20", "91.108.4.0/22"}
+
+var (
+	// ErrWebhookStarted describes a try to start already started or stopped Webhook.
+	ErrWebhookStarted = errors.New("webhook already started")
+
+	// ErrWebhookNotStarted describes a try to stop not started or already stopped Webhook.
+	ErrWebhookNotStarted = errors.New("webhook is not started")
+
+	// ErrWebhookServerClosed describes the server of Webhook which is stopped without error or started on the
+	// closed listener.
+	ErrWebhookServerClosed = errors.New("webhook server closed")
+)
+
+// NewWebhook creates a new Webhook for bot with provided setWebhook parameters. Only requests with the path of
+// p.URL are accepted. If p.SecretToken is set, then requests without the same HeaderSecretToken are rejected.
+func NewWebhook(b *Bot, p SetWebhook) *Webhook {
+	u := http.AcquireURI()
+	defer http.ReleaseURI(u)
+	u.Update(p.URL)
+
+	return &Webhook{
+		bot:     b,
+		params:  p,
+		path:    append([]byte(nil), u.Path()...),
+		updates: make(UpdatesChannel, 100), // NOTE(toby3d): channel size by default GetUpdates.Limit parameter
+		stop:    make(chan struct{}),
+	}
+}
+
+// TelegramNetworks returns subnets which are used by Telegram for webhook requests.
+func TelegramNetworks() []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(telegramNetworks))
+
+	for _, cidr := range telegramNetworks {
+		_, network, _ := net.ParseCIDR(cidr)
+		networks = append(networks, network)
+	}
+
+	return networks
+}
+
+// SetErrorHandler sets the callback for errors which can not be returned to Telegram or Start caller: failures of
+// the running server, invalid payloads, offset storage errors and failed replies. Without the callback they are only
+// printed in debug logs.
+func (w *Webhook) SetErrorHandler(h ErrorHandler) {
+	w.errorHandler = h
+}
+
+// SetAllowedNetworks accepts requests only from addresses in provided networks, like TelegramNetworks. Requests from
+// any address are accepted by default.
+func (w *Webhook) SetAllowedNetworks(networks ...*net.IPNet) {
+	w.networks = networks
+}
+
+// SetRemoteIPHeader sets the header name (like X-Forwarded-For or X-Real-IP) which contains the source address of
+// requests behind reverse proxy. The last address of the header is used, since it's added by the nearest proxy, so
+// make sure that the webhook is not reachable bypassing the proxy.
+func (w *Webhook) SetRemoteIPHeader(name string) {
+	w.ipHeader = name
+}
+
+// SetReplyHandler sets the handler which receives updates instead of the updates channel and can reply to them in
+// the webhook response.
+func (w *Webhook) SetReplyHandler(h ReplyHandler) {
+	w.replyHandler = h
+}
+
+// SetOffsetStorage sets the storage of the last processed update identifier. It's updated by Done calls or after
+// ReplyHandler. Updates which are already handling or processed, like redelivered by Telegram after failed or timed
+// out response, are confirmed without handling. Telegram delivers updates in order only over one connection, so
+// set MaxConnections of SetWebhook to 1, otherwise the update which is delivered after the next one may be skipped
+// after restart.
+func (w *Webhook) SetOffsetStorage(s OffsetStorage) {
+	w.offsets = &offsetTracker{storage: s}
+}
+
+// Done marks the update received from the updates channel as processed in the offset storage. Call it after
+// handling of each update, if the storage is set.
+func (w *Webhook) Done(ctx context.Context, u *Update) error {
+	if w.offsets == nil || u == nil {
+		return nil
+	}
+
+	return w.offsets.done(ctx, u.ID)
+}
+
+// Start starts the server on ln in the background, sets the webhook and returns channel of incoming updates, which
+// is also stored in the Updates field of the bot. Channel will be closed after Stop call. If the server can not be
+// started, like with invalid certificate or closed listener, Start returns its error and the Webhook is stopped.
+//
+// If cert argument is provided by two strings (["path/to/cert.file", "path/to/cert.key"]), then TLS server will be
+// created by this filepaths.
+func (w *Webhook) Start(ctx context.Context, ln net.Listener, crt ...string) (UpdatesChannel, error) {
+	w.mu.Lock()
+	if w.srv != nil || w.started || w.stopped {
+		w.mu.Unlock()
+
+		return nil, ErrWebhookStarted
+	}
+
+	w.srv = &http.Server{
+		Name:              w.bot.Username,
+		Concurrency:       w.params.MaxConnections,
+		Handler:           w.handle,
+		ReduceMemoryUsage: true,
+		CloseOnShutdown:   true,
+		ConnState:         w.trackConn,
+	}
+	w.ln, w.done = ln, make(chan struct{})
+	w.mu.Unlock()
+
+	// NOTE(toby3d): Serve does not report that it's ready, so its early errors are checked before setWebhook.
+	if len(crt) == 2 {
+		if err := w.srv.AppendCert(crt[0], crt[1]); err != nil {
+			w.abort()
+
+			return nil, fmt.Errorf("webhook server: %w", err)
+		}
+	}
+
+	if err := checkListener(ln); err != nil {
+		w.abort()
+
+		return nil, fmt.Errorf("webhook server: %w", err)
+	}
+
+	go func(srv *http.Server, done chan struct{}) {
+		defer close(done)
+
+		var err error
+
+		switch {
+		case len(crt) == 2:
+			err = srv.ServeTLS(ln, "", "") // NOTE(toby3d): certificate is already appended
+		default:
+			err = srv.Serve(ln)
+		}
+
+		// NOTE(toby3d): Serve returns nil if the listener is closed.
+		if err == nil {
+			err = ErrWebhookServerClosed
+		}
+
+		w.mu.RLock()
+		stopped := w.stopped
+		w.mu.RUnlock()
+
+		if !stopped {
+			w.errorHandler.handle("Webhook server failed:", fmt.Errorf("webhook server: %w", err))
+		}
+	}(w.srv, w.done)
+
+	return w.Open(ctx)
+}
+
+// checkListener returns ErrWebhookServerClosed if ln is already closed. Only listeners of the system sockets, like
+// net.TCPListener, can be checked, others are always valid.
+func checkListener(ln net.Listener) error {
+	sc, ok := ln.(syscall.Conn)
+	if !ok {
+		return nil
+	}
+
+	rc, err := sc.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	if err = rc.Control(func(uintptr) {}); err != nil {
+		return fmt.Errorf("%w: %v", ErrWebhookServerClosed, err)
+	}
+
+	return nil
+}
+
+// Open sets the webhook and returns channel of incoming updates for handlers of the Webhook which are mounted into
+// the existing server by ServeHTTP or HandleFastHTTP, so the bot can share one port with other handlers. Channel is
+// also stored in the Updates field of the bot and will be closed after Stop call.
+func (w *Webhook) Open(ctx context.Context) (UpdatesChannel, error) {
+	w.mu.Lock()
+	if w.started || w.stopped {
+		w.mu.Unlock()
+
+		return nil, ErrWebhookStarted
+	}
+
+	// NOTE(toby3d): Telegram can send updates right after setWebhook, so accept them before the response.
+	w.started = true
+	w.bot.Updates = w.updates
+	w.mu.Unlock()
+
+	if _, err := w.bot.SetWebhookContext(ctx, w.params); err != nil {
+		_ = w.Stop(ctx)
+
+		return nil, err
+	}
+
+	return w.updates, nil
+}
+
+// Stop stops the Webhook in order: stops accepting new requests, answers to requests which are waiting for the full
+// updates channel with the 503 Service Unavailable status, waits until already accepted updates will be delivered
+// and closes the updates channel. The server created by Start is shut down, mounted handlers answer with the 503
+// Service Unavailable status after Stop. The webhook itself stays set, so Telegram keeps pending updates until next
+// start. If ctx is done before that, Stop returns ctx error.
+func (w *Webhook) Stop(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.started || w.stopped {
+		w.mu.Unlock()
+
+		return ErrWebhookNotStarted
+	}
+
+	w.stopped = true
+	srv, ln, done := w.srv, w.ln, w.done
+	close(w.stop)
+	w.mu.Unlock()
+
+	shutdown := make(chan error, 1)
+	go func() {
+		var err error
+
+		if srv != nil {
+			err = srv.Shutdown()
+			_ = ln.Close() // NOTE(toby3d): Shutdown does nothing if Serve is not running yet
+			<-done
+		}
+
+		w.wg.Wait()
+		close(w.updates)
+		shutdown <- err
+	}()
+
+	// NOTE(toby3d): Shutdown waits for all connections, but keep-alive connections can be idle infinitely.
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		w.closeIdleConns()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-shutdown:
+			return err
+		case <-ticker.C:
+		}
+	}
+}
+
+// abort stops the Webhook which is failed to start before Open, so the updates channel is closed.
+func (w *Webhook) abort() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stopped = true
+	close(w.stop)
+	close(w.updates)
+}
+
+// ServeHTTP implements the net/http.Handler interface, so the Webhook can be mounted into the existing net/http
+// server, like ServeMux. Updates are accepted only between Open and Stop calls.
+func (w *Webhook) ServeHTTP(rw nethttp.ResponseWriter, r *nethttp.Request) {
+	var ip net.IP
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = net.ParseIP(host)
+	}
+
+	// NOTE(toby3d): body is read only from the allowed requests and only up to the limit, like in fasthttp.Server.
+	if status := w.check(r.Method, ip, r.Header.Get); status != http.StatusOK {
+		rw.WriteHeader(status)
+
+		return
+	}
+
+	body, err := ioutil.ReadAll(nethttp.MaxBytesReader(rw, r.Body, webhookMaxBodySize))
+	if err != nil {
+		status := http.StatusBadRequest
+		if int64(len(body)) == webhookMaxBodySize {
+			status = http.StatusRequestEntityTooLarge
+		}
+
+		rw.WriteHeader(status)
+
+		return
+	}
+
+	status, resp := w.serve(r.Context(), body)
+	if len(resp) > 0 {
+		rw.Header().Set("Content-Type", contentType(status))
+	}
+
+	rw.WriteHeader(status)
+	_, _ = rw.Write(resp)
+}
+
+// HandleFastHTTP is a fasthttp.RequestHandler, so the Webhook can be mounted into the existing fasthttp server or
+// router, like fasthttp/router. Updates are accepted only between Open and Stop calls.
+func (w *Webhook) HandleFastHTTP(ctx *http.RequestCtx) {
+	header := func(key string) string { return string(ctx.Request.Header.Peek(key)) }
+
+	if status := w.check(string(ctx.Method()), ctx.RemoteIP(), header); status != http.StatusOK {
+		ctx.SetStatusCode(status)
+
+		return
+	}
+
+	status, resp := w.serve(ctx, ctx.Request.Body())
+	if len(resp) > 0 {
+		ctx.SetContentType(contentType(status))
+	}
+
+	ctx.SetStatusCode(status)
+	ctx.SetBody(resp)
+}
+
+// handle is a handler of the server created by Start.
+func (w *Webhook) handle(ctx *http.RequestCtx) {
+	if !bytes.HasPrefix(ctx.Path(), w.path) {
+		dlog.Ln("Unsupported request path:", string(ctx.Path()))
+		ctx.SetStatusCode(http.StatusNotFound)
+
+		return
+	}
+
+	w.HandleFastHTTP(ctx)
+}
+
+// check checks method, address and secret token of the webhook request before reading of its body and returns
+// the 200 OK status for allowed requests or error status otherwise.
+func (w *Webhook) check(method string, ip net.IP, header func(key string) string) int {
+	if method != http.MethodPost {
+		return http.StatusMethodNotAllowed
+	}
+
+	if ip = w.remoteIP(ip, header); !w.isAllowedIP(ip) {
+		dlog.Ln("Webhook request from not allowed address:", ip)
+
+		return http.StatusForbidden
+	}
+
+	if w.params.SecretToken != "" && subtle.ConstantTimeCompare([]byte(header(HeaderSecretToken)),
+		[]byte(w.params.SecretToken)) != 1 {
+		return http.StatusUnauthorized
+	}
+
+	return http.StatusOK
+}
+
+// serve handles the body of the checked webhook request and returns the response status with the optional body:
+// method call in JSON for successful requests or error description otherwise.
+func (w *Webhook) serve(ctx context.Context, body []byte) (int, []byte) {
+	upd := new(Update)
+	if err := w.bot.marshler.Unmarshal(body, upd); err != nil {
+		w.errorHandler.handle("Invalid webhook payload:", fmt.Errorf("webhook payload: %w", err))
+
+		return http.StatusBadRequest, []byte(err.Error())
+	}
+
+	// NOTE(toby3d): updates channel is closed only after all accepted requests are done.
+	w.mu.RLock()
+	if !w.started || w.stopped {
+		w.mu.RUnlock()
+
+		return http.StatusServiceUnavailable, nil
+	}
+	w.wg.Add(1)
+	w.mu.RUnlock()
+
+	defer w.wg.Done()
+
+	if w.offsets != nil {
+		ok, err := w.offsets.begin(ctx, upd.ID)
+		if err != nil {
+			w.errorHandler.handle("Failed to track update offset:", err)
+
+			return http.StatusInternalServerError, nil
+		}
+
+		// NOTE(toby3d): redelivered update must be confirmed, otherwise Telegram will repeat it.
+		if !ok {
+			dlog.Ln("Webhook update is already processed:", upd.ID)
+
+			return http.StatusOK, nil
+		}
+	}
+
+	w.bot.notifyMigration(ctx, upd)
+
+	var (
+		status   = http.StatusOK
+		response []byte
+	)
+
+	if w.replyHandler != nil {
+		response = w.reply(ctx, w.replyHandler(ctx, upd))
+	} else {
+		status = w.enqueue(ctx, upd)
+	}
+
+	if w.offsets == nil {
+		return status, response
+	}
+
+	switch {
+	case status != http.StatusOK:
+		w.offsets.abort(upd.ID)
+	case w.replyHandler != nil:
+		if err := w.offsets.done(ctx, upd.ID); err != nil {
+			w.errorHandler.handle("Failed to track update offset:", err)
+		}
+	}
+
+	return status, response
+}
+
+// enqueue sends upd into the updates channel according to QueuePolicy and returns status of the webhook response.
+func (w *Webhook) enqueue(ctx context.Context, upd *Update) int {
+	select {
+	case w.updates <- upd:
+		return http.StatusOK
+	default:
+	}
+
+	switch w.QueuePolicy {
+	case QueueDrop:
+		dlog.Ln("Updates channel is full, update dropped:", upd.ID)
+	case QueueReject:
+		return http.StatusServiceUnavailable
+	default:
+		select {
+		case w.updates <- upd:
+		case <-w.stop:
+			return http.StatusServiceUnavailable
+		case <-ctx.Done():
+			return http.StatusServiceUnavailable
+		}
+	}
+
+	return http.StatusOK
+}
+
+// reply returns the call encoded for the webhook response.
+func (w *Webhook) reply(ctx context.Context, call *Call) []byte {
+	if call == nil {
+		return nil
+	}
+
+	// NOTE(toby3d): files can not be uploaded in the webhook response, so send them by the separate request.
+	if len(call.Files) > 0 {
+		if _, err := w.bot.invoke(ctx, call); err != nil {
+			w.errorHandler.handle("Failed to reply to webhook:", fmt.Errorf("webhook reply: %w", err))
+		}
+
+		return nil
+	}
+
+	method, err := w.bot.marshler.Marshal(call.Method)
+	if err != nil {
+		w.errorHandler.handle("Failed to reply to webhook:", fmt.Errorf("webhook reply: %w", err))
+
+		return nil
+	}
+
+	src, err := w.bot.marshler.Marshal(call.Payload)
+	if err == nil {
+		src, err = prependField(w.bot.marshler, src, "method", method)
+	}
+
+	if err != nil {
+		w.errorHandler.handle("Failed to reply to webhook:", fmt.Errorf("webhook reply: %w", err))
+
+		return nil
+	}
+
+	return src
+}
+
+func (w *Webhook) remoteIP(ip net.IP, header func(key string) string) net.IP {
+	if w.ipHeader == "" {
+		return ip
+	}
+
+	addrs := strings.Split(header(w.ipHeader), ",")
+
+	return net.ParseIP(strings.TrimSpace(addrs[len(addrs)-1]))
+}
+
+func (w *Webhook) isAllowedIP(ip net.IP) bool {
+	if len(w.networks) == 0 {
+		return true
+	}
+
+	for _, network := range w.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *Webhook) trackConn(conn net.Conn, state http.ConnState) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.idle == nil {
+		w.idle = make(map[net.Conn]struct{})
+	}
+
+	// NOTE(toby3d): new connections without requests are also idle, clients can open them in advance.
+	switch state {
+	case http.StateNew, http.StateIdle:
+		w.idle[conn] = struct{}{}
+	default:
+		delete(w.idle, conn)
+	}
+}
+
+func (w *Webhook) closeIdleConns() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for conn := range w.idle {
+		_ = conn.Close()
+		delete(w.idle, conn)
+	}
+}
+
+// contentType returns type of the webhook response body with status.
+func contentType(status int) string {
+	if status == http.StatusOK {
+		return "application/json"
+	}
+
+	return "text/plain; charset=utf-8"
+}
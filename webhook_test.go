@@ -0,0 +1,470 @@
+package telegram_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fasthttp/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/toby3d/telegram/v5"
+	"gitlab.com/toby3d/telegram/v5/telegramtest"
+)
+
+func newWebhook(t *testing.T, srv *telegramtest.Server) (*telegram.Webhook, net.Listener) {
+	t.Helper()
+
+	bot, err := srv.NewBot()
+	assert.NoError(t, err)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	return telegram.NewWebhook(bot, telegram.SetWebhook{URL: "http://" + ln.Addr().String() + "/webhook"}), ln
+}
+
+func TestWebhook(t *testing.T) {
+	srv := telegramtest.NewServer("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+	defer srv.Close()
+
+	w, ln := newWebhook(t, srv)
+
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+
+	w.SetErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		errs = append(errs, err)
+	})
+
+	updates, err := w.Start(context.Background(), ln)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://"+ln.Addr().String()+"/webhook", srv.WebhookInfo().URL)
+
+	_, err = w.Start(context.Background(), ln)
+	assert.True(t, errors.Is(err, telegram.ErrWebhookStarted))
+
+	_, err = srv.AddMessage(42, &telegram.User{ID: 42, FirstName: "Maxim"}, "hello")
+	assert.NoError(t, err)
+
+	update := <-updates
+	assert.Equal(t, "hello", update.Message.Text)
+
+	t.Run("invalid payload", func(t *testing.T) {
+		resp, err := http.Post("http://"+ln.Addr().String()+"/webhook", "application/json",
+			strings.NewReader(`{"update_id":`))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		mu.Lock()
+		assert.Len(t, errs, 1)
+		mu.Unlock()
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		resp, err := http.Post("http://"+ln.Addr().String()+"/admin", "application/json",
+			strings.NewReader(`{"update_id":1}`))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	assert.NoError(t, w.Stop(context.Background()))
+
+	_, ok := <-updates
+	assert.False(t, ok)
+	assert.True(t, errors.Is(w.Stop(context.Background()), telegram.ErrWebhookNotStarted))
+}
+
+func TestWebhookStartError(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		crt      []string
+		expError error
+	}{{
+		name:     "closed listener",
+		expError: telegram.ErrWebhookServerClosed,
+	}, {
+		name: "invalid certificate",
+		crt:  []string{"testdata/missing.crt", "testdata/missing.key"},
+	}} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			srv := telegramtest.NewServer("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+			defer srv.Close()
+
+			bot, err := srv.NewBot()
+			assert.NoError(t, err)
+
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			assert.NoError(t, err)
+
+			if tc.expError != nil {
+				assert.NoError(t, ln.Close())
+			} else {
+				defer ln.Close()
+			}
+
+			u := fasthttp.AcquireURI()
+			defer fasthttp.ReleaseURI(u)
+			u.Update("https://" + ln.Addr().String() + "/webhook")
+
+			updates, shutdown := bot.NewWebhookChannel(u, telegram.SetWebhook{URL: u.String()}, ln, tc.crt...)
+
+			_, ok := <-updates
+			assert.False(t, ok)
+
+			err = shutdown()
+			if assert.Error(t, err) && tc.expError != nil {
+				assert.True(t, errors.Is(err, tc.expError), err)
+			}
+
+			assert.Empty(t, srv.WebhookInfo().URL)
+		})
+	}
+}
+
+func TestWebhookQueuePolicy(t *testing.T) {
+	srv := telegramtest.NewServer("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+	defer srv.Close()
+
+	fill := func(t *testing.T, updates telegram.UpdatesChannel) {
+		t.Helper()
+
+		for len(updates) < cap(updates) {
+			assert.NoError(t, srv.AddUpdate(new(telegram.Update)))
+		}
+	}
+
+	t.Run("drop", func(t *testing.T) {
+		w, ln := newWebhook(t, srv)
+		w.QueuePolicy = telegram.QueueDrop
+
+		updates, err := w.Start(context.Background(), ln)
+		assert.NoError(t, err)
+
+		fill(t, updates)
+		assert.NoError(t, srv.AddUpdate(new(telegram.Update)))
+		assert.Equal(t, cap(updates), len(updates))
+		assert.NoError(t, w.Stop(context.Background()))
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		w, ln := newWebhook(t, srv)
+		w.QueuePolicy = telegram.QueueReject
+
+		updates, err := w.Start(context.Background(), ln)
+		assert.NoError(t, err)
+
+		fill(t, updates)
+		assert.Error(t, srv.AddUpdate(new(telegram.Update)))
+		assert.NoError(t, w.Stop(context.Background()))
+	})
+
+	t.Run("block", func(t *testing.T) {
+		w, ln := newWebhook(t, srv)
+
+		updates, err := w.Start(context.Background(), ln)
+		assert.NoError(t, err)
+
+		fill(t, updates)
+
+		blocked := make(chan error)
+		go func() { blocked <- srv.AddUpdate(new(telegram.Update)) }()
+
+		// NOTE(toby3d): read one update, so the blocked request will be accepted.
+		<-updates
+		assert.NoError(t, <-blocked)
+
+		go func() { blocked <- srv.AddUpdate(new(telegram.Update)) }()
+
+		assert.NoError(t, w.Stop(context.Background()))
+		assert.Error(t, <-blocked)
+		assert.Equal(t, cap(updates), len(updates))
+	})
+}
+
+func TestWebhookSecretToken(t *testing.T) {
+	srv := telegramtest.NewServer("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+	defer srv.Close()
+
+	bot, err := srv.NewBot()
+	assert.NoError(t, err)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	u := "http://" + ln.Addr().String() + "/webhook"
+	w := telegram.NewWebhook(bot, telegram.SetWebhook{URL: u, SecretToken: "s3cr3t"})
+
+	updates, err := w.Start(context.Background(), ln)
+	assert.NoError(t, err)
+
+	defer w.Stop(context.Background())
+
+	_, err = srv.AddMessage(42, &telegram.User{ID: 42, FirstName: "Maxim"}, "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", (<-updates).Message.Text)
+
+	for _, token := range []string{"", "wrong"} {
+		req, err := http.NewRequest(http.MethodPost, u, strings.NewReader(`{"update_id":100500}`))
+		assert.NoError(t, err)
+
+		if token != "" {
+			req.Header.Set(telegram.HeaderSecretToken, token)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	}
+
+	assert.Len(t, updates, 0)
+}
+
+func TestWebhookAllowedNetworks(t *testing.T) {
+	srv := telegramtest.NewServer("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+	defer srv.Close()
+
+	w, ln := newWebhook(t, srv)
+	w.SetAllowedNetworks(telegram.TelegramNetworks()...)
+	w.SetRemoteIPHeader("X-Forwarded-For")
+
+	updates, err := w.Start(context.Background(), ln)
+	assert.NoError(t, err)
+
+	defer w.Stop(context.Background())
+
+	assert.Error(t, srv.AddUpdate(new(telegram.Update)), "direct request without proxy header")
+
+	t.Run("reverse proxy", func(t *testing.T) {
+		for _, tc := range []struct {
+			name      string
+			header    string
+			expStatus int
+		}{{
+			name:      "telegram",
+			header:    "149.154.167.220",
+			expStatus: http.StatusOK,
+		}, {
+			name:      "spoofed",
+			header:    "149.154.167.220, 10.0.0.1",
+			expStatus: http.StatusForbidden,
+		}, {
+			name:      "empty",
+			header:    "",
+			expStatus: http.StatusForbidden,
+		}} {
+			tc := tc
+			t.Run(tc.name, func(t *testing.T) {
+				req, err := http.NewRequest(http.MethodPost, "http://"+ln.Addr().String()+"/webhook",
+					strings.NewReader(`{"update_id":100500}`))
+				assert.NoError(t, err)
+				req.Header.Set("X-Forwarded-For", tc.header)
+
+				resp, err := http.DefaultClient.Do(req)
+				assert.NoError(t, err)
+				resp.Body.Close()
+				assert.Equal(t, tc.expStatus, resp.StatusCode)
+			})
+		}
+
+		assert.Len(t, updates, 1)
+	})
+}
+
+func TestWebhookServeHTTP(t *testing.T) {
+	srv := telegramtest.NewServer("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+	defer srv.Close()
+
+	bot, err := srv.NewBot()
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(rw http.ResponseWriter, r *http.Request) { rw.WriteHeader(http.StatusNoContent) })
+
+	api := httptest.NewServer(mux)
+	defer api.Close()
+
+	w := telegram.NewWebhook(bot, telegram.SetWebhook{URL: api.URL + "/webhook"})
+	mux.Handle("/webhook", w)
+
+	resp, err := http.Post(api.URL+"/webhook", "application/json", strings.NewReader(`{"update_id":100500}`))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "not opened webhook")
+
+	updates, err := w.Open(context.Background())
+	assert.NoError(t, err)
+
+	_, err = srv.AddMessage(42, &telegram.User{ID: 42, FirstName: "Maxim"}, "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", (<-updates).Message.Text)
+
+	resp, err = http.Get(api.URL + "/healthz")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, err = http.Post(api.URL+"/webhook", "application/json", strings.NewReader(strings.Repeat(" ", 4<<20+1)))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	resp, err = http.Get(api.URL + "/webhook")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	assert.NoError(t, w.Stop(context.Background()))
+
+	_, ok := <-updates
+	assert.False(t, ok)
+	assert.Error(t, srv.AddUpdate(new(telegram.Update)), "stopped webhook")
+}
+
+func TestWebhookHandleFastHTTP(t *testing.T) {
+	srv := telegramtest.NewServer("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+	defer srv.Close()
+
+	bot, err := srv.NewBot()
+	assert.NoError(t, err)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	w := telegram.NewWebhook(bot, telegram.SetWebhook{URL: "http://" + ln.Addr().String() + "/bot/webhook"})
+
+	r := router.New()
+	r.POST("/bot/webhook", w.HandleFastHTTP)
+
+	go fasthttp.Serve(ln, r.Handler)
+
+	defer ln.Close()
+
+	updates, err := w.Open(context.Background())
+	assert.NoError(t, err)
+
+	_, err = srv.AddMessage(42, &telegram.User{ID: 42, FirstName: "Maxim"}, "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", (<-updates).Message.Text)
+	assert.NoError(t, w.Stop(context.Background()))
+}
+
+func TestWebhookReplyHandler(t *testing.T) {
+	srv := telegramtest.NewServer("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+	defer srv.Close()
+
+	w, ln := newWebhook(t, srv)
+	w.SetReplyHandler(func(ctx context.Context, u *telegram.Update) *telegram.Call {
+		if !u.IsMessage() {
+			return nil
+		}
+
+		return &telegram.Call{
+			Method:  telegram.MethodSendMessage,
+			Payload: telegram.NewMessage(telegram.ChatID{ID: u.Message.Chat.ID}, "Hello, "+u.Message.From.FirstName),
+		}
+	})
+
+	updates, err := w.Start(context.Background(), ln)
+	assert.NoError(t, err)
+
+	defer w.Stop(context.Background())
+
+	t.Run("reply", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "http://"+ln.Addr().String()+"/webhook", strings.NewReader(
+			`{"update_id":1,"message":{"message_id":1,"date":0,"chat":{"id":42,"type":"private"},`+
+				`"from":{"id":42,"is_bot":false,"first_name":"Maxim"},"text":"hi"}}`))
+		assert.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		var reply map[string]interface{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&reply))
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		assert.Equal(t, telegram.MethodSendMessage, reply["method"])
+		assert.Equal(t, float64(42), reply["chat_id"])
+		assert.Equal(t, "Hello, Maxim", reply["text"])
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		resp, err := http.Post("http://"+ln.Addr().String()+"/webhook", "application/json",
+			strings.NewReader(`{"update_id":2}`))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Zero(t, resp.ContentLength)
+	})
+
+	t.Run("server", func(t *testing.T) {
+		_, err := srv.AddMessage(24, &telegram.User{ID: 24, FirstName: "John"}, "hi")
+		assert.NoError(t, err)
+
+		messages := srv.Messages(24)
+		if assert.Len(t, messages, 1) {
+			assert.Equal(t, "Hello, John", messages[0].Text)
+		}
+	})
+
+	assert.Len(t, updates, 0)
+}
+
+func TestWebhookOffsetStorage(t *testing.T) {
+	srv := telegramtest.NewServer("123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11")
+	defer srv.Close()
+
+	bot, err := srv.NewBot()
+	assert.NoError(t, err)
+
+	// NOTE(toby3d): the previous process is crashed after handling the update 10.
+	storage := telegram.NewMemoryStorage()
+	assert.NoError(t, storage.SetLastUpdateID(context.Background(), 10))
+
+	api := httptest.NewServer(nil)
+	defer api.Close()
+
+	w := telegram.NewWebhook(bot, telegram.SetWebhook{URL: api.URL})
+	w.SetOffsetStorage(storage)
+	api.Config.Handler = w
+
+	updates, err := w.Open(context.Background())
+	assert.NoError(t, err)
+
+	for _, id := range []string{"10", "11", "9", "11", "12"} {
+		resp, err := http.Post(api.URL, "application/json", strings.NewReader(`{"update_id":`+id+`}`))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, id)
+	}
+
+	assert.NoError(t, w.Stop(context.Background()))
+
+	var handled []int64
+	for u := range updates {
+		handled = append(handled, u.ID)
+		assert.NoError(t, w.Done(context.Background(), u))
+	}
+
+	assert.Equal(t, []int64{11, 12}, handled)
+
+	last, err := storage.GetLastUpdateID(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), last)
+}